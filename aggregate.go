@@ -0,0 +1,262 @@
+package go_dbsearch
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Aggregation functions accepted in Metric.Func (case-insensitive).
+const (
+	AggCount = "count"
+	AggSum   = "sum"
+	AggAvg   = "avg"
+	AggMin   = "min"
+	AggMax   = "max"
+)
+
+// aliasRe restricts metric aliases to plain SQL identifiers (no dots, no quoting needed).
+var aliasRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Metric is a single aggregate in an AggregateRequest, e.g. {"func":"sum","field":"amount"}.
+//
+// Field may be empty (or "*") for count. Alias names the result column; it defaults to
+// "count" for COUNT(*) and "<func>_<field>" otherwise.
+type Metric struct {
+	Func  string `json:"func"`
+	Field string `json:"field"`
+	Alias string `json:"alias"`
+}
+
+// AggregateRequest is the JSON payload for AggregateHandlerWithOptions.
+//
+// Filters and Query use the same language as AdvancedSearchRequest.
+// Buckets group by computed keys (date histogram, numeric ranges) in addition to GroupBy.
+// Having filters reference metric aliases; Sort may reference group-by fields, bucket or metric aliases.
+type AggregateRequest struct {
+	Filters    *FilterGroup `json:"filters"`
+	Query      string       `json:"query"`
+	GroupBy    []string     `json:"group_by"`
+	Buckets    []Bucket     `json:"buckets"`
+	Metrics    []Metric     `json:"metrics"`
+	Having     *FilterGroup `json:"having"`
+	Sort       []SortOption `json:"sort"`
+	Pagination Pagination   `json:"pagination"`
+}
+
+// ValidateAggregateRequest validates the aggregation part of req against opts and normalizes it in-place.
+//
+//   - GroupBy and Bucket fields must be in Options.GroupableFields.
+//   - Metric fields must be in Options.AggregatableFields (except for count).
+//   - Having filters must reference metric aliases.
+//   - Sort fields must be group-by fields, bucket or metric aliases.
+//
+// If no metrics are given, a single count metric is used.
+// Filters are not validated here; use Validator.ValidateFilterGroup as for search requests.
+func ValidateAggregateRequest(req *AggregateRequest, opts *Options) error {
+	if req == nil {
+		return errors.New("aggregate request is nil")
+	}
+	if opts == nil {
+		return errors.New("options is required")
+	}
+
+	groupable := NewValidator(opts.GroupableFields)
+	aggregatable := NewValidator(opts.AggregatableFields)
+
+	outputs := map[string]struct{}{}
+
+	for i, field := range req.GroupBy {
+		field = strings.TrimSpace(field)
+		if err := groupable.ValidateField(field); err != nil {
+			return fmt.Errorf("group_by: %w", err)
+		}
+		req.GroupBy[i] = field
+		outputs[field] = struct{}{}
+	}
+
+	for i := range req.Buckets {
+		b := &req.Buckets[i]
+		if err := validateBucket(b, opts); err != nil {
+			return fmt.Errorf("bucket: %w", err)
+		}
+		if _, dup := outputs[b.Alias]; dup {
+			return fmt.Errorf("duplicate output name: %q", b.Alias)
+		}
+		outputs[b.Alias] = struct{}{}
+	}
+
+	if len(req.Metrics) == 0 {
+		req.Metrics = []Metric{{Func: AggCount}}
+	}
+
+	for i := range req.Metrics {
+		m := &req.Metrics[i]
+		m.Func = strings.ToLower(strings.TrimSpace(m.Func))
+		m.Field = strings.TrimSpace(m.Field)
+
+		switch m.Func {
+		case AggCount:
+			if m.Field == "*" {
+				m.Field = ""
+			}
+		case AggSum, AggAvg, AggMin, AggMax:
+			if m.Field == "" {
+				return fmt.Errorf("metric %s requires a field", m.Func)
+			}
+		default:
+			return fmt.Errorf("aggregate function is not allowed: %q", m.Func)
+		}
+
+		if m.Field != "" {
+			if err := aggregatable.ValidateField(m.Field); err != nil {
+				return fmt.Errorf("metric: %w", err)
+			}
+		}
+
+		if m.Alias == "" {
+			m.Alias = defaultMetricAlias(*m)
+		}
+		if !aliasRe.MatchString(m.Alias) {
+			return fmt.Errorf("invalid metric alias: %q", m.Alias)
+		}
+		if _, dup := outputs[m.Alias]; dup {
+			return fmt.Errorf("duplicate output name: %q", m.Alias)
+		}
+		outputs[m.Alias] = struct{}{}
+	}
+
+	aliases := make(map[string]struct{}, len(req.Metrics))
+	for _, m := range req.Metrics {
+		aliases[m.Alias] = struct{}{}
+	}
+	if err := NewValidator(aliases).ValidateFilterGroup(req.Having); err != nil {
+		return fmt.Errorf("having: %w", err)
+	}
+	if err := NormalizeFilterGroupValues(req.Having, NewValueCaster(nil)); err != nil {
+		return fmt.Errorf("having: %w", err)
+	}
+
+	sortable := NewValidator(outputs)
+	for i := range req.Sort {
+		norm, err := sortable.ValidateSortOption(req.Sort[i])
+		if err != nil {
+			return fmt.Errorf("sort: %w", err)
+		}
+		req.Sort[i] = norm
+	}
+
+	return nil
+}
+
+// validateAggregate is ValidateAggregateRequest against s. Group-by, bucket and metric fields
+// withheld by Permissions are rejected with ErrFieldDenied.
+func (s *Snapshot) validateAggregate(req *AggregateRequest) error {
+	if req == nil {
+		return errors.New("aggregate request is nil")
+	}
+	for _, field := range req.GroupBy {
+		if err := s.deniedField(s.opts.GroupableFields, field); err != nil {
+			return fmt.Errorf("group_by: %w", err)
+		}
+	}
+	for _, b := range req.Buckets {
+		if err := s.deniedField(s.opts.GroupableFields, b.Field); err != nil {
+			return fmt.Errorf("bucket: %w", err)
+		}
+	}
+	for _, m := range req.Metrics {
+		if field := strings.TrimSpace(m.Field); field != "" && field != "*" {
+			if err := s.deniedField(s.opts.AggregatableFields, field); err != nil {
+				return fmt.Errorf("metric: %w", err)
+			}
+		}
+	}
+	return ValidateAggregateRequest(req, s.opts)
+}
+
+// ApplyAggregate applies a validated AggregateRequest (see ValidateAggregateRequest) to a GORM query.
+//
+// Client filters and the quick search term are applied first, then SELECT/GROUP BY/HAVING,
+// ORDER BY and pagination (capped by Options.MaxLimit).
+func ApplyAggregate(db *gorm.DB, req AggregateRequest, opts *Options) *gorm.DB {
+	tx := db
+
+	if req.Filters != nil {
+		tx = req.Filters.Apply(tx)
+	}
+	tx = ApplyQuickSearch(tx, req.Query, opts)
+
+	var vars []interface{}
+	selects := make([]string, 0, len(req.GroupBy)+len(req.Buckets)+len(req.Metrics))
+	selects = append(selects, req.GroupBy...)
+	for _, b := range req.Buckets {
+		expr, bvars, err := bucketExpr(b, dialectName(tx), req.Filters)
+		if err != nil {
+			_ = tx.AddError(err)
+			return tx
+		}
+		selects = append(selects, expr+" AS "+b.Alias)
+		vars = append(vars, bvars...)
+	}
+
+	exprs := make(map[string]string, len(req.Metrics))
+	for _, m := range req.Metrics {
+		expr := metricExpr(m)
+		exprs[m.Alias] = expr
+		selects = append(selects, expr+" AS "+m.Alias)
+	}
+
+	tx = tx.Select(strings.Join(selects, ", "), vars...)
+	for _, field := range req.GroupBy {
+		tx = tx.Group(field)
+	}
+	for _, b := range req.Buckets {
+		tx = tx.Group(b.Alias)
+	}
+
+	if req.Having != nil && (len(req.Having.And) > 0 || len(req.Having.Or) > 0) {
+		having := req.Having.applyWith(newScopeDB(tx), func(f Filter, scope *gorm.DB) *gorm.DB {
+			expr, ok := exprs[f.Field]
+			if !ok {
+				return scope
+			}
+			return applyFilterExpr(scope, expr, f.Op, f.Value)
+		})
+		tx = tx.Having(having)
+	}
+
+	for _, s := range req.Sort {
+		tx = tx.Order(s.Field + " " + s.Direction)
+	}
+
+	limit := req.Pagination.Limit
+	if opts != nil && opts.MaxLimit > 0 && limit > opts.MaxLimit {
+		limit = opts.MaxLimit
+	}
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	if req.Pagination.Offset > 0 {
+		tx = tx.Offset(req.Pagination.Offset)
+	}
+
+	return tx
+}
+
+func defaultMetricAlias(m Metric) string {
+	if m.Field == "" {
+		return m.Func
+	}
+	return m.Func + "_" + strings.ReplaceAll(m.Field, ".", "_")
+}
+
+func metricExpr(m Metric) string {
+	if m.Field == "" {
+		return "COUNT(*)"
+	}
+	return strings.ToUpper(m.Func) + "(" + m.Field + ")"
+}
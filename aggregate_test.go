@@ -0,0 +1,120 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type aggOrder struct {
+	ID     uint
+	Status string
+	Amount float64
+}
+
+func setupAggregateTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open DB: %v", err)
+	}
+	if err := db.AutoMigrate(&aggOrder{}); err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	db.Create(&[]aggOrder{
+		{Status: "active", Amount: 10},
+		{Status: "active", Amount: 20},
+		{Status: "active", Amount: 30},
+		{Status: "pending", Amount: 5},
+	})
+	return db
+}
+
+func aggregateTestOptions() *Options {
+	return NewOptions([]string{"status", "amount"}).
+		WithGroupableFields("status").
+		WithAggregatableFields("amount")
+}
+
+func TestValidateAggregateRequest_Errors(t *testing.T) {
+	cases := []AggregateRequest{
+		{GroupBy: []string{"amount"}},
+		{Metrics: []Metric{{Func: "median", Field: "amount"}}},
+		{Metrics: []Metric{{Func: "sum", Field: "status"}}},
+		{Metrics: []Metric{{Func: "sum"}}},
+		{Metrics: []Metric{{Func: "count", Alias: "x; DROP"}}},
+		{Having: &FilterGroup{And: []FilterGroupOrLeaf{{Filter: &Filter{Field: "amount", Op: ">", Value: 1}}}}},
+		{Sort: []SortOption{{Field: "amount", Direction: "asc"}}},
+	}
+	for i, req := range cases {
+		if err := ValidateAggregateRequest(&req, aggregateTestOptions()); err == nil {
+			t.Fatalf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestApplyAggregate_GroupByHaving(t *testing.T) {
+	db := setupAggregateTestDB(t)
+	opts := aggregateTestOptions()
+
+	req := AggregateRequest{
+		GroupBy: []string{"status"},
+		Metrics: []Metric{{Func: "count"}, {Func: "SUM", Field: "amount"}},
+		Having:  &FilterGroup{And: []FilterGroupOrLeaf{{Filter: &Filter{Field: "count", Op: "gt", Value: 1}}}},
+		Sort:    []SortOption{{Field: "sum_amount", Direction: "desc"}},
+	}
+	if err := ValidateAggregateRequest(&req, opts); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	var rows []map[string]interface{}
+	if err := ApplyAggregate(db.Model(&aggOrder{}), req, opts).Find(&rows).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %+v", rows)
+	}
+	if rows[0]["status"] != "active" || rows[0]["count"] != int64(3) || rows[0]["sum_amount"] != float64(60) {
+		t.Fatalf("unexpected row: %+v", rows[0])
+	}
+}
+
+func TestAggregateHandlerWithOptions(t *testing.T) {
+	db := setupAggregateTestDB(t)
+	router := gin.New()
+	router.POST("/orders/aggregate", AggregateHandlerWithOptions[aggOrder](db, aggOrder{}, aggregateTestOptions()))
+
+	body := []byte(`{
+		"filters": {"and": [{"filter": {"field": "amount", "op": "gte", "value": 10}}]},
+		"group_by": ["status"],
+		"metrics": [{"func": "avg", "field": "amount", "alias": "avg_amount"}]
+	}`)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/orders/aggregate", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["avg_amount"] != float64(20) {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/orders/aggregate", bytes.NewBufferString(`{"group_by": ["id"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for non-groupable field, got %d", w.Code)
+	}
+}
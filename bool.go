@@ -0,0 +1,104 @@
+package go_dbsearch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BoolVocabulary lists the accepted spellings of FieldTypeBool values, matched case-insensitively
+// after trimming. See DefaultBoolVocabulary.
+//
+// Besides true and false, bool filters are tri-state:
+//   - Null matches NULL: eq renders "col IS NULL" and in adds "OR col IS NULL". A JSON null is always
+//     treated as Null. false matches only false, never NULL.
+//   - Any matches every row: GET filters using it are dropped; in JSON filter groups it renders an
+//     always-true condition so OR groups stay correct.
+//
+// Null and Any are accepted with eq and in only.
+type BoolVocabulary struct {
+	True  []string `json:"true,omitempty" yaml:"true,omitempty"`
+	False []string `json:"false,omitempty" yaml:"false,omitempty"`
+	Null  []string `json:"null,omitempty" yaml:"null,omitempty"`
+	Any   []string `json:"any,omitempty" yaml:"any,omitempty"`
+}
+
+// DefaultBoolVocabulary returns the vocabulary used when Options.BoolValues is nil:
+// true/t/1/yes/y/on, false/f/0/no/n/off, null and any.
+func DefaultBoolVocabulary() BoolVocabulary {
+	return BoolVocabulary{
+		True:  []string{"true", "t", "1", "yes", "y", "on"},
+		False: []string{"false", "f", "0", "no", "n", "off"},
+		Null:  []string{"null"},
+		Any:   []string{"any"},
+	}
+}
+
+func (b BoolVocabulary) clone() BoolVocabulary {
+	return BoolVocabulary{
+		True:  cloneSlice(b.True),
+		False: cloneSlice(b.False),
+		Null:  cloneSlice(b.Null),
+		Any:   cloneSlice(b.Any),
+	}
+}
+
+// sqlNull is the cast value of a tri-state null: eq renders IS NULL and in adds OR IS NULL.
+type sqlNull struct{}
+
+// matchAny is the cast value of a tri-state any: it matches every row.
+type matchAny struct{}
+
+// newBoolLookup indexes a vocabulary by lowercased spelling. Empty or repeated spellings are errors.
+func newBoolLookup(b BoolVocabulary) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	for _, set := range []struct {
+		words []string
+		value interface{}
+	}{{b.True, true}, {b.False, false}, {b.Null, sqlNull{}}, {b.Any, matchAny{}}} {
+		for _, w := range set.words {
+			key := strings.ToLower(strings.TrimSpace(w))
+			if key == "" {
+				return nil, fmt.Errorf("BoolValues: empty spelling")
+			}
+			if _, dup := m[key]; dup {
+				return nil, fmt.Errorf("BoolValues: %q is listed twice", w)
+			}
+			m[key] = set.value
+		}
+	}
+	if len(b.True) == 0 || len(b.False) == 0 {
+		return nil, fmt.Errorf("BoolValues: true and false need at least one spelling")
+	}
+	return m, nil
+}
+
+func (c *ValueCaster) castBool(field, raw string) (interface{}, error) {
+	if v, ok := c.bools[strings.ToLower(strings.TrimSpace(raw))]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("invalid bool for %s: %q", field, raw)
+}
+
+// checkTriState rejects null/any values with operators other than eq and in. An in list
+// containing any matches every row and collapses to any.
+func checkTriState(field, op string, v interface{}) (interface{}, error) {
+	switch vv := v.(type) {
+	case sqlNull, matchAny:
+		if op != "=" {
+			return nil, fmt.Errorf("%s: null and any values require eq or in", field)
+		}
+	case []interface{}:
+		for _, item := range vv {
+			switch item.(type) {
+			case sqlNull, matchAny:
+				if op != "IN" {
+					return nil, fmt.Errorf("%s: null and any values require eq or in", field)
+				}
+			}
+			if _, ok := item.(matchAny); ok {
+				return matchAny{}, nil
+			}
+		}
+	}
+	return v, nil
+}
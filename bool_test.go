@@ -0,0 +1,135 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestValueCaster_BoolVocabulary(t *testing.T) {
+	c := NewValueCaster(NewOptions([]string{"ok"}).WithFieldTypes(map[string]FieldType{"ok": FieldTypeBool}))
+	for raw, want := range map[string]interface{}{
+		"on": true, "YES": true, " 1 ": true, "t": true,
+		"off": false, "No": false, "0": false,
+		"null": sqlNull{}, "ANY": matchAny{},
+	} {
+		got, err := c.CastFromString("ok", raw)
+		if err != nil || got != want {
+			t.Fatalf("%q: got %#v, %v; want %#v", raw, got, err, want)
+		}
+	}
+	if _, err := c.CastFromString("ok", "maybe"); err == nil {
+		t.Fatal("expected error for unknown spelling")
+	}
+	if v, err := c.NormalizeJSONValue("ok", nil); err != nil || v != (sqlNull{}) {
+		t.Fatalf("JSON null: got %#v, %v", v, err)
+	}
+
+	opts := NewOptions([]string{"ok"}).
+		WithFieldTypes(map[string]FieldType{"ok": FieldTypeBool}).
+		WithBoolValues(BoolVocabulary{True: []string{"ja"}, False: []string{"nein"}})
+	c = NewValueCaster(opts)
+	if v, err := c.CastFromString("ok", "Ja"); err != nil || v != true {
+		t.Fatalf("custom vocabulary: got %#v, %v", v, err)
+	}
+	if _, err := c.CastFromString("ok", "yes"); err == nil {
+		t.Fatal("custom vocabulary should replace the defaults")
+	}
+	if _, err := c.CastFromString("ok", "any"); err == nil {
+		t.Fatal("any is not in the custom vocabulary")
+	}
+
+	opts.WithBoolValues(BoolVocabulary{True: []string{"y"}, False: []string{"Y"}})
+	if _, err := NewValidatorFromOptions(opts); err == nil {
+		t.Fatal("expected error for duplicate spellings")
+	}
+}
+
+type flagRow struct {
+	ID       int
+	Verified *bool
+}
+
+func setupFlagDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&flagRow{}); err != nil {
+		t.Fatal(err)
+	}
+	yes, no := true, false
+	db.Create(&[]flagRow{{ID: 1, Verified: &yes}, {ID: 2, Verified: &no}, {ID: 3}})
+	return db
+}
+
+func flagIDs(t *testing.T, tx *gorm.DB) string {
+	t.Helper()
+	var rows []flagRow
+	if err := tx.Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, strconv.Itoa(r.ID))
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestBoolTriState_Query(t *testing.T) {
+	db := setupFlagDB(t)
+	opts := NewOptions([]string{"verified"}).WithFieldTypes(map[string]FieldType{"verified": FieldTypeBool})
+
+	for filter, want := range map[string]string{
+		"filter[verified]=on":             "1",
+		"filter[verified]=off":            "2",
+		"filter[verified]=null":           "3",
+		"filter[verified]=any":            "1,2,3",
+		"filter[verified:in]=yes,null":    "1,3",
+		"filter[verified:in]=no,any":      "1,2,3",
+		"filter[verified:gt]=null":        "1,2,3", // invalid, skipped
+		"filter[verified:between]=0,null": "1,2,3", // invalid, skipped
+	} {
+		values, _ := url.ParseQuery(filter)
+		q, err := ParseQueryWithOptions(values, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", filter, err)
+		}
+		if got := flagIDs(t, ApplyWithOptions(db.Model(&flagRow{}), q, opts)); got != want {
+			t.Fatalf("%s: got %s, want %s", filter, got, want)
+		}
+	}
+}
+
+func TestBoolTriState_JSON(t *testing.T) {
+	db := setupFlagDB(t)
+	opts := NewOptions([]string{"verified", "id"}).WithFieldTypes(map[string]FieldType{"verified": FieldTypeBool, "id": FieldTypeInt})
+	h := NewAdvancedSearchHandler[flagRow](db, flagRow{}, opts)
+
+	for body, want := range map[string]string{
+		`{"filters": {"and": [{"filter": {"field": "verified", "op": "eq", "value": null}}]}}`:                                                      `"ID":3`,
+		`{"filters": {"and": [{"filter": {"field": "verified", "op": "in", "value": [false, null]}}]}}`:                                             `"ID":2`,
+		`{"filters": {"or": [{"filter": {"field": "id", "op": "eq", "value": 1}}, {"filter": {"field": "verified", "op": "eq", "value": "any"}}]}}`: `"ID":3`,
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(body)))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Fatalf("%s: got %d %s, want %s", body, w.Code, w.Body.String(), want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(
+		`{"filters": {"and": [{"filter": {"field": "verified", "op": "gt", "value": null}}]}}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for null with gt, got %d: %s", w.Code, w.Body.String())
+	}
+}
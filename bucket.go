@@ -0,0 +1,486 @@
+package go_dbsearch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Date histogram intervals accepted in Bucket.Interval (case-insensitive).
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// Bucket groups aggregation rows by a computed key instead of a raw column value.
+//
+// Date histogram (FieldTypeDate/FieldTypeCalendarDate/FieldTypeTime fields):
+//   - Interval: "day", "week" (ISO, starting Monday) or "month"
+//   - Timezone: IANA name used to compute local day boundaries (default "UTC"); calendar dates
+//     already are days and accept no other zone
+//   - Keys are "YYYY-MM-DD" strings (first day of the week/month).
+//
+// Numeric buckets (integer, FieldTypeFloat64 and FieldTypeDecimal fields), one of:
+//   - Ranges: half-open [From, To) ranges, e.g. 0-10, 10-50, 50+ (rows outside all ranges get a NULL key)
+//   - Step: fixed-width buckets keyed by their lower bound (floor(value/step)*step)
+//
+// Alias names the key column; it defaults to "<field>_<interval>" or "<field>_bucket".
+type Bucket struct {
+	Field    string        `json:"field"`
+	Alias    string        `json:"alias"`
+	Interval string        `json:"interval"`
+	Timezone string        `json:"timezone"`
+	Ranges   []BucketRange `json:"ranges"`
+	Step     float64       `json:"step"`
+
+	// calendar marks a FieldTypeCalendarDate field, bucketed without a zone conversion.
+	calendar bool
+}
+
+// BucketRange is a half-open numeric range [From, To). A nil bound is unbounded.
+// Key defaults to "from-to", "*-to" or "from+".
+type BucketRange struct {
+	Key  string   `json:"key"`
+	From *float64 `json:"from"`
+	To   *float64 `json:"to"`
+}
+
+// validateBucket validates a bucket against Options.GroupableFields and FieldTypes and normalizes it in-place.
+func validateBucket(b *Bucket, opts *Options) error {
+	b.Field = strings.TrimSpace(b.Field)
+	if err := NewValidator(opts.GroupableFields).ValidateField(b.Field); err != nil {
+		return err
+	}
+
+	switch t := opts.FieldTypes[b.Field]; t {
+	case FieldTypeDate, FieldTypeCalendarDate, FieldTypeTime:
+		b.Interval = strings.ToLower(strings.TrimSpace(b.Interval))
+		switch b.Interval {
+		case IntervalDay, IntervalWeek, IntervalMonth:
+		default:
+			return fmt.Errorf("invalid interval for %s: %q (use day, week or month)", b.Field, b.Interval)
+		}
+		if b.Timezone == "" {
+			b.Timezone = "UTC"
+		}
+		b.calendar = t == FieldTypeCalendarDate
+		if b.calendar && b.Timezone != "UTC" {
+			return fmt.Errorf("timezone is not supported for calendar date field %s", b.Field)
+		}
+		if _, err := time.LoadLocation(b.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %q", b.Timezone)
+		}
+		if len(b.Ranges) > 0 || b.Step != 0 {
+			return fmt.Errorf("ranges/step are not supported for date field %s", b.Field)
+		}
+		if b.Alias == "" {
+			b.Alias = strings.ReplaceAll(b.Field, ".", "_") + "_" + b.Interval
+		}
+	case FieldTypeInt, FieldTypeInt64, FieldTypeUint, FieldTypeUint64, FieldTypeFloat64, FieldTypeDecimal:
+		if b.Interval != "" {
+			return fmt.Errorf("interval is not supported for numeric field %s", b.Field)
+		}
+		if (len(b.Ranges) > 0) == (b.Step != 0) {
+			return fmt.Errorf("numeric bucket on %s requires either ranges or step", b.Field)
+		}
+		if b.Step < 0 {
+			return fmt.Errorf("bucket step must be positive for %s", b.Field)
+		}
+		for i := range b.Ranges {
+			r := &b.Ranges[i]
+			if r.From == nil && r.To == nil {
+				return fmt.Errorf("bucket range on %s needs from or to", b.Field)
+			}
+			if r.From != nil && r.To != nil && *r.From >= *r.To {
+				return fmt.Errorf("bucket range on %s: from must be < to", b.Field)
+			}
+			if r.Key == "" {
+				r.Key = defaultRangeKey(*r)
+			}
+		}
+		if b.Alias == "" {
+			b.Alias = strings.ReplaceAll(b.Field, ".", "_") + "_bucket"
+		}
+	default:
+		return fmt.Errorf("bucket field %s needs a date, time or numeric FieldType", b.Field)
+	}
+
+	if !aliasRe.MatchString(b.Alias) {
+		return fmt.Errorf("invalid bucket alias: %q", b.Alias)
+	}
+	return nil
+}
+
+// bucketExpr returns the SQL key expression (and its vars) for a validated bucket on the given dialect.
+// filters are the request's normalized filters; they bound the zone offsets a date bucket needs.
+func bucketExpr(b Bucket, dialect string, filters *FilterGroup) (string, []interface{}, error) {
+	if b.Interval != "" {
+		return dateBucketExpr(b, dialect, filters)
+	}
+	if len(b.Ranges) > 0 {
+		return rangeBucketExpr(b)
+	}
+	return stepBucketExpr(b, dialect)
+}
+
+// checkDateBuckets reports the first date bucket of a validated request that cannot be rendered
+// on dialect, e.g. one whose zone changes offset too often within the filtered time range.
+func checkDateBuckets(req AggregateRequest, dialect string) error {
+	for _, b := range req.Buckets {
+		if b.Interval == "" {
+			continue
+		}
+		if _, _, err := dateBucketExpr(b, dialect, req.Filters); err != nil {
+			return fmt.Errorf("bucket: %w", err)
+		}
+	}
+	return nil
+}
+
+func dateBucketExpr(b Bucket, dialect string, filters *FilterGroup) (string, []interface{}, error) {
+	loc, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if b.calendar {
+		return calendarBucketExpr(b, dialect)
+	}
+
+	switch dialect {
+	case "sqlite":
+		// SQLite has no named time zones; pick the zone's UTC offset in effect at each row.
+		mod, vars, err := zoneOffsetExpr(b, loc, filters, func(off int) interface{} {
+			return strconv.Itoa(off/60) + " minutes"
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		switch b.Interval {
+		case IntervalDay:
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s, %s)", b.Field, mod), vars, nil
+		case IntervalWeek:
+			return fmt.Sprintf("date(%s, %s, '-6 days', 'weekday 1')", b.Field, mod), vars, nil
+		default:
+			return fmt.Sprintf("strftime('%%Y-%%m-01', %s, %s)", b.Field, mod), vars, nil
+		}
+	case "postgres":
+		return fmt.Sprintf("to_char(date_trunc(?, %s AT TIME ZONE ?), 'YYYY-MM-DD')", b.Field),
+			[]interface{}{b.Interval, b.Timezone}, nil
+	case "mysql":
+		// CONVERT_TZ with offsets avoids depending on MySQL's time zone tables.
+		offset, vars, err := zoneOffsetExpr(b, loc, filters, utcOffsetHHMM)
+		if err != nil {
+			return "", nil, err
+		}
+		local := fmt.Sprintf("CONVERT_TZ(%s, '+00:00', %s)", b.Field, offset)
+		switch b.Interval {
+		case IntervalDay:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", local), vars, nil
+		case IntervalWeek:
+			return fmt.Sprintf("DATE_FORMAT(DATE_SUB(%s, INTERVAL WEEKDAY(%s) DAY), '%%Y-%%m-%%d')", local, local),
+				append(append([]interface{}{}, vars...), vars...), nil
+		default:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-01')", local), vars, nil
+		}
+	default:
+		return "", nil, fmt.Errorf("date buckets are not supported for dialect %q", dialect)
+	}
+}
+
+// calendarBucketExpr is dateBucketExpr for DATE columns, which hold days and need no zone offset.
+func calendarBucketExpr(b Bucket, dialect string) (string, []interface{}, error) {
+	switch dialect {
+	case "sqlite":
+		switch b.Interval {
+		case IntervalDay:
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s)", b.Field), nil, nil
+		case IntervalWeek:
+			return fmt.Sprintf("date(%s, '-6 days', 'weekday 1')", b.Field), nil, nil
+		default:
+			return fmt.Sprintf("strftime('%%Y-%%m-01', %s)", b.Field), nil, nil
+		}
+	case "postgres":
+		// A bare date would be promoted to timestamptz in the session zone.
+		return fmt.Sprintf("to_char(date_trunc(?, CAST(%s AS timestamp)), 'YYYY-MM-DD')", b.Field),
+			[]interface{}{b.Interval}, nil
+	case "mysql":
+		switch b.Interval {
+		case IntervalDay:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", b.Field), nil, nil
+		case IntervalWeek:
+			return fmt.Sprintf("DATE_FORMAT(DATE_SUB(%s, INTERVAL WEEKDAY(%s) DAY), '%%Y-%%m-%%d')", b.Field, b.Field), nil, nil
+		default:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-01')", b.Field), nil, nil
+		}
+	default:
+		return "", nil, fmt.Errorf("date buckets are not supported for dialect %q", dialect)
+	}
+}
+
+func rangeBucketExpr(b Bucket) (string, []interface{}, error) {
+	var (
+		sb   strings.Builder
+		vars []interface{}
+	)
+	sb.WriteString("CASE")
+	for _, r := range b.Ranges {
+		var conds []string
+		if r.From != nil {
+			conds = append(conds, b.Field+" >= ?")
+			vars = append(vars, *r.From)
+		}
+		if r.To != nil {
+			conds = append(conds, b.Field+" < ?")
+			vars = append(vars, *r.To)
+		}
+		sb.WriteString(" WHEN " + strings.Join(conds, " AND ") + " THEN ?")
+		vars = append(vars, r.Key)
+	}
+	sb.WriteString(" ELSE NULL END")
+	return sb.String(), vars, nil
+}
+
+func stepBucketExpr(b Bucket, dialect string) (string, []interface{}, error) {
+	if b.Step <= 0 {
+		return "", nil, errors.New("bucket step must be positive")
+	}
+	if dialect == "sqlite" {
+		// SQLite has no FLOOR without math functions: truncate, then step down for negative fractions.
+		q := fmt.Sprintf("(%s * 1.0 / ?)", b.Field)
+		return fmt.Sprintf("(CAST(%s AS INTEGER) - (%s < CAST(%s AS INTEGER))) * ?", q, q, q),
+			[]interface{}{b.Step, b.Step, b.Step, b.Step}, nil
+	}
+	return fmt.Sprintf("FLOOR(%s / ?) * ?", b.Field), []interface{}{b.Step, b.Step}, nil
+}
+
+func defaultRangeKey(r BucketRange) string {
+	switch {
+	case r.From == nil:
+		return "*-" + formatBound(*r.To)
+	case r.To == nil:
+		return formatBound(*r.From) + "+"
+	default:
+		return formatBound(*r.From) + "-" + formatBound(*r.To)
+	}
+}
+
+func formatBound(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Zone offset changes are resolved between these instants when the filters leave the bucket field
+// unbounded; rows outside use the nearest offset.
+var (
+	zoneHistoryStart = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	zoneHistoryEnd   = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// maxZoneOffsetChanges caps the CASE branches of zoneOffsetExpr (two bind vars each, evaluated for
+// every row): 48 changes are about 24 years of DST.
+const maxZoneOffsetChanges = 48
+
+// zoneOffsetExpr returns an SQL expression (and its vars) yielding format(offset) for the UTC
+// offset loc had at the instant in b.Field: a bare placeholder when the offset is constant over
+// the time range filters allow, otherwise a CASE over the offset changes in that range, so rows on
+// both sides of a DST change bucket correctly.
+//
+// It fails when the range holds more than maxZoneOffsetChanges changes, or is unbounded on a side
+// where the zone still changes offset near the end of the resolved history.
+func zoneOffsetExpr(b Bucket, loc *time.Location, filters *FilterGroup, format func(off int) interface{}) (string, []interface{}, error) {
+	from, to := filters.timeSpan(b.Field)
+	lo, hi := from, to
+	if lo.IsZero() {
+		lo = zoneHistoryStart
+	}
+	if hi.IsZero() {
+		hi = zoneHistoryEnd
+	}
+	if hi.Before(lo) {
+		hi = lo
+	}
+
+	changes, offsets := zoneOffsetsIn(loc, lo, hi)
+	if len(changes) > maxZoneOffsetChanges {
+		return "", nil, fmt.Errorf("%s changes UTC offset more than %d times in the time range of %s; filter %s to a shorter range",
+			b.Timezone, maxZoneOffsetChanges, b.Field, b.Field)
+	}
+	if n := len(changes); n > 0 &&
+		(from.IsZero() && changes[0].Before(lo.AddDate(1, 0, 0)) || to.IsZero() && !changes[n-1].Before(hi.AddDate(-1, 0, 0))) {
+		return "", nil, fmt.Errorf("%s changes UTC offset outside %d-%d; filter %s to a time range",
+			b.Timezone, zoneHistoryStart.Year(), zoneHistoryEnd.Year(), b.Field)
+	}
+	if len(changes) == 0 {
+		return "?", []interface{}{format(offsets[0])}, nil
+	}
+
+	var sb strings.Builder
+	vars := make([]interface{}, 0, 2*len(changes)+1)
+	sb.WriteString("CASE")
+	for i, at := range changes {
+		sb.WriteString(" WHEN " + b.Field + " < ? THEN ?")
+		vars = append(vars, at, format(offsets[i]))
+	}
+	sb.WriteString(" ELSE ? END")
+	vars = append(vars, format(offsets[len(offsets)-1]))
+	return sb.String(), vars, nil
+}
+
+// timeSpan returns the [from, to) range g's top-level AND filters allow for field, with a zero
+// bound on each unconstrained side. Only normalized values (time.Time, Range) are considered.
+func (g *FilterGroup) timeSpan(field string) (from, to time.Time) {
+	if g == nil {
+		return from, to
+	}
+	lower := func(v interface{}) {
+		if t, ok := v.(time.Time); ok && (from.IsZero() || t.After(from)) {
+			from = t
+		}
+	}
+	upper := func(v interface{}, inclusive bool) {
+		t, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		if inclusive {
+			t = t.Add(time.Nanosecond)
+		}
+		if to.IsZero() || t.Before(to) {
+			to = t
+		}
+	}
+
+	for _, item := range g.And {
+		f := item.Filter
+		if f == nil || f.fieldName() != field {
+			continue
+		}
+		op, _ := NormalizeOperator(f.Op)
+		if r, ok := f.Value.(Range); ok {
+			switch op {
+			case "=", "BETWEEN":
+				lower(r.From)
+				upper(r.To, false)
+			case ">":
+				lower(r.To)
+			case ">=":
+				lower(r.From)
+			case "<":
+				upper(r.From, false)
+			case "<=":
+				upper(r.To, false)
+			}
+			continue
+		}
+		switch op {
+		case "=":
+			lower(f.Value)
+			upper(f.Value, true)
+		case ">", ">=":
+			lower(f.Value)
+		case "<":
+			upper(f.Value, false)
+		case "<=":
+			upper(f.Value, true)
+		case "BETWEEN":
+			if lo, hi, ok := normalizeBetweenValue(f.Value); ok {
+				lower(lo)
+				upper(hi, true)
+			}
+		}
+	}
+	return from, to
+}
+
+// zoneOffsetsIn is zoneOffsets for [from, to), served from the memoized zone history when the
+// range lies inside it.
+func zoneOffsetsIn(loc *time.Location, from, to time.Time) (changes []time.Time, offsets []int) {
+	if from.Before(zoneHistoryStart) || to.After(zoneHistoryEnd) {
+		return zoneOffsets(loc, from, to, maxZoneOffsetChanges)
+	}
+
+	var e zoneOffsetsEntry
+	if c, ok := zoneOffsetsCache.Load(loc.String()); ok {
+		e = c.(zoneOffsetsEntry)
+	} else {
+		e.changes, e.offsets = zoneOffsets(loc, zoneHistoryStart, zoneHistoryEnd, -1)
+		zoneOffsetsCache.Store(loc.String(), e)
+	}
+	i := sort.Search(len(e.changes), func(k int) bool { return e.changes[k].After(from) })
+	j := sort.Search(len(e.changes), func(k int) bool { return !e.changes[k].Before(to) })
+	if j < i {
+		j = i
+	}
+	return e.changes[i:j], e.offsets[i : j+1]
+}
+
+// zoneOffsets returns the instants in [from, to) at which loc's UTC offset (in seconds) changes,
+// and the offsets before, between and after them (one more than changes). It stops after limit
+// changes unless limit is negative.
+//
+// The zone is sampled weekly and each change is bisected to the second; Time.ZoneBounds is not
+// used because it misreports bounds past the explicit transitions of some zone files.
+func zoneOffsets(loc *time.Location, from, to time.Time, limit int) (changes []time.Time, offsets []int) {
+	offsetAt := func(t time.Time) int {
+		_, off := t.In(loc).Zone()
+		return off
+	}
+	const step = 7 * 24 * time.Hour
+	off := offsetAt(from)
+	offsets = append(offsets, off)
+	for t := from; t.Before(to) && (limit < 0 || len(changes) <= limit); t = t.Add(step) {
+		next := t.Add(step)
+		if next.After(to) {
+			next = to
+		}
+		if offsetAt(next) == off {
+			continue
+		}
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+			if offsetAt(mid) == off {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		off = offsetAt(hi)
+		changes = append(changes, hi.UTC())
+		offsets = append(offsets, off)
+	}
+	return changes, offsets
+}
+
+type zoneOffsetsEntry struct {
+	changes []time.Time
+	offsets []int
+}
+
+// zoneOffsetsCache memoizes the zoneHistoryStart-zoneHistoryEnd offsets per zone name; time zone
+// data does not change at runtime.
+var zoneOffsetsCache sync.Map
+
+func utcOffsetHHMM(off int) interface{} {
+	sign := '+'
+	if off < 0 {
+		sign = '-'
+		off = -off
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, off/3600, off%3600/60)
+}
+
+// dialectName returns the GORM dialector name ("sqlite", "postgres", "mysql", ...).
+func dialectName(db *gorm.DB) string {
+	if db == nil || db.Dialector == nil {
+		return ""
+	}
+	return db.Dialector.Name()
+}
@@ -0,0 +1,236 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type bucketOrder struct {
+	ID        uint
+	Amount    float64
+	CreatedAt time.Time
+}
+
+func setupBucketTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open DB: %v", err)
+	}
+	if err := db.AutoMigrate(&bucketOrder{}); err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	db.Create(&[]bucketOrder{
+		{Amount: -3, CreatedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{Amount: 5, CreatedAt: time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)},
+		{Amount: 25, CreatedAt: time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)},
+		{Amount: 75, CreatedAt: time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC)},
+	})
+	return db
+}
+
+func bucketTestOptions() *Options {
+	return NewOptions([]string{"amount", "created_at"}).
+		WithFieldTypes(map[string]FieldType{"amount": FieldTypeFloat64, "created_at": FieldTypeTime}).
+		WithGroupableFields("amount", "created_at")
+}
+
+func runBucketAggregate(t *testing.T, db *gorm.DB, b Bucket, filters ...Filter) map[string]int64 {
+	t.Helper()
+	req := AggregateRequest{Buckets: []Bucket{b}}
+	if len(filters) > 0 {
+		req.Filters = &FilterGroup{}
+		for i := range filters {
+			req.Filters.And = append(req.Filters.And, FilterGroupOrLeaf{Filter: &filters[i]})
+		}
+	}
+	if err := ValidateAggregateRequest(&req, bucketTestOptions()); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	var rows []map[string]interface{}
+	if err := ApplyAggregate(db.Model(&bucketOrder{}), req, bucketTestOptions()).Find(&rows).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	out := map[string]int64{}
+	for _, row := range rows {
+		key := "<nil>"
+		switch k := row[req.Buckets[0].Alias].(type) {
+		case string:
+			key = k
+		case int64:
+			key = formatBound(float64(k))
+		case float64:
+			key = formatBound(k)
+		}
+		out[key] = toInt64(row["count"])
+	}
+	return out
+}
+
+func TestBucket_DateHistogram(t *testing.T) {
+	db := setupBucketTestDB(t)
+
+	cases := []struct {
+		bucket Bucket
+		want   map[string]int64
+	}{
+		{Bucket{Field: "created_at", Interval: "day"}, map[string]int64{"2024-03-01": 2, "2024-03-05": 1, "2024-04-02": 1}},
+		{Bucket{Field: "created_at", Interval: "day", Timezone: "Asia/Tehran"}, map[string]int64{"2024-03-01": 1, "2024-03-02": 1, "2024-03-05": 1, "2024-04-02": 1}},
+		{Bucket{Field: "created_at", Interval: "week"}, map[string]int64{"2024-02-26": 2, "2024-03-04": 1, "2024-04-01": 1}},
+		{Bucket{Field: "created_at", Interval: "month"}, map[string]int64{"2024-03-01": 3, "2024-04-01": 1}},
+	}
+	// Asia/Tehran has kept one offset since 2022; the lower bound skips its earlier DST changes.
+	since := Filter{Field: "created_at", Op: ">=", Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	for _, tc := range cases {
+		got := runBucketAggregate(t, db, tc.bucket, since)
+		if len(got) != len(tc.want) {
+			t.Fatalf("%+v: expected %v, got %v", tc.bucket, tc.want, got)
+		}
+		for k, n := range tc.want {
+			if got[k] != n {
+				t.Fatalf("%+v: expected %v, got %v", tc.bucket, tc.want, got)
+			}
+		}
+	}
+}
+
+func TestBucket_DateHistogramAcrossDST(t *testing.T) {
+	db := setupBucketTestDB(t)
+	db.Where("1 = 1").Delete(&bucketOrder{})
+	// Both rows are 23:30 local in New York: EST (-05:00) in March, EDT (-04:00) in July.
+	db.Create(&[]bucketOrder{
+		{CreatedAt: time.Date(2024, 3, 9, 4, 30, 0, 0, time.UTC)},
+		{CreatedAt: time.Date(2024, 7, 1, 3, 30, 0, 0, time.UTC)},
+	})
+
+	got := runBucketAggregate(t, db, Bucket{Field: "created_at", Interval: "day", Timezone: "America/New_York"},
+		Filter{Field: "created_at", Op: ">=", Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Filter{Field: "created_at", Op: "<", Value: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if len(got) != 2 || got["2024-03-08"] != 1 || got["2024-06-30"] != 1 {
+		t.Fatalf("expected local days on both sides of the DST change, got %v", got)
+	}
+}
+
+func TestBucket_DateHistogramZoneSpan(t *testing.T) {
+	b := Bucket{Field: "created_at", Interval: "week", Timezone: "America/New_York"}
+	year := &FilterGroup{And: []FilterGroupOrLeaf{{Filter: &Filter{Field: "created_at", Op: "between", Value: Range{
+		From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}}}}
+
+	// One year holds two DST changes: two CASE branches and the ELSE offset, twice for MySQL weeks.
+	for dialect, want := range map[string]int{"sqlite": 5, "mysql": 10} {
+		_, vars, err := bucketExpr(b, dialect, year)
+		if err != nil || len(vars) != want {
+			t.Fatalf("%s: expected %d vars, got %d (%v)", dialect, want, len(vars), err)
+		}
+	}
+
+	// Unbounded or decades-long ranges would need hundreds of branches.
+	decades := &FilterGroup{And: []FilterGroupOrLeaf{
+		{Filter: &Filter{Field: "created_at", Op: ">=", Value: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{Filter: &Filter{Field: "created_at", Op: "<", Value: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}}
+	for _, filters := range []*FilterGroup{nil, decades} {
+		if _, _, err := bucketExpr(b, "sqlite", filters); err == nil {
+			t.Fatalf("%v: expected a time range error", filters)
+		}
+	}
+
+	// Zones settled on one offset need no range.
+	b.Timezone = "Asia/Tokyo"
+	if expr, vars, err := bucketExpr(b, "sqlite", nil); err != nil || len(vars) != 1 {
+		t.Fatalf("expected a single offset, got %q %v (%v)", expr, vars, err)
+	}
+}
+
+func TestBucket_NumericRangesAndStep(t *testing.T) {
+	db := setupBucketTestDB(t)
+	ten, fifty := 10.0, 50.0
+
+	got := runBucketAggregate(t, db, Bucket{Field: "amount", Ranges: []BucketRange{
+		{To: &ten},
+		{From: &ten, To: &fifty},
+		{From: &fifty},
+	}})
+	if got["*-10"] != 2 || got["10-50"] != 1 || got["50+"] != 1 {
+		t.Fatalf("unexpected range buckets: %v", got)
+	}
+
+	got = runBucketAggregate(t, db, Bucket{Field: "amount", Step: 10})
+	if got["-10"] != 1 || got["0"] != 1 || got["20"] != 1 || got["70"] != 1 {
+		t.Fatalf("unexpected step buckets: %v", got)
+	}
+}
+
+func TestBucket_ValidationErrors(t *testing.T) {
+	cases := []Bucket{
+		{Field: "created_at", Interval: "hour"},
+		{Field: "created_at", Interval: "day", Timezone: "Mars/Base"},
+		{Field: "amount"},
+		{Field: "amount", Interval: "day"},
+		{Field: "id", Interval: "day"},
+	}
+	for _, b := range cases {
+		req := AggregateRequest{Buckets: []Bucket{b}}
+		if err := ValidateAggregateRequest(&req, bucketTestOptions()); err == nil {
+			t.Fatalf("%+v: expected error", b)
+		}
+	}
+}
+
+func TestBucket_HandlerZoneSpan(t *testing.T) {
+	db := setupBucketTestDB(t)
+	router := gin.New()
+	router.POST("/orders/aggregate", AggregateHandlerWithOptions[bucketOrder](db, bucketOrder{}, bucketTestOptions()))
+
+	bucket := `"buckets": [{"field": "created_at", "interval": "day", "timezone": "America/New_York"}]`
+	cases := []struct {
+		body string
+		want int
+	}{
+		{`{` + bucket + `}`, http.StatusBadRequest},
+		{`{"filters": {"and": [{"filter": {"field": "created_at", "op": "between", "value": "2024-01-01T00:00:00Z,2024-12-31T00:00:00Z"}}]}, ` + bucket + `}`, http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/orders/aggregate", bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.body, tc.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestBucket_CalendarDate(t *testing.T) {
+	opts := NewOptions([]string{"day"}).
+		WithFieldTypes(map[string]FieldType{"day": FieldTypeCalendarDate}).
+		WithGroupableFields("day")
+
+	req := AggregateRequest{Buckets: []Bucket{{Field: "day", Interval: "month", Timezone: "America/New_York"}}}
+	if err := ValidateAggregateRequest(&req, opts); err == nil {
+		t.Fatal("expected an error for a time zone on a calendar date")
+	}
+
+	req = AggregateRequest{Buckets: []Bucket{{Field: "day", Interval: "month"}}}
+	if err := ValidateAggregateRequest(&req, opts); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	for dialect, want := range map[string]string{
+		"sqlite":   "strftime('%Y-%m-01', day)",
+		"postgres": "date_trunc(?, CAST(day AS timestamp))",
+		"mysql":    "DATE_FORMAT(day, '%Y-%m-01')",
+	} {
+		if expr, _, err := bucketExpr(req.Buckets[0], dialect, nil); err != nil || !strings.Contains(expr, want) {
+			t.Fatalf("%s: expected %q in %q (%v)", dialect, want, expr, err)
+		}
+	}
+}
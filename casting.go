@@ -1,6 +1,7 @@
 package go_dbsearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -9,28 +10,62 @@ import (
 
 // FieldType describes the expected type of a searchable field.
 // It is used to cast query-string values and to normalize JSON values.
+// Besides the constants below, types can be added with RegisterFieldType (see fieldtype.go).
 type FieldType string
 
 const (
 	// FieldTypeString treats values as strings.
 	FieldTypeString FieldType = "string"
-	// FieldTypeInt casts values to int.
+	// FieldTypeInt casts values to int. Integer types reject fractional values ("1.9") and values
+	// out of range instead of truncating them.
 	FieldTypeInt FieldType = "int"
 	// FieldTypeInt64 casts values to int64.
 	FieldTypeInt64 FieldType = "int64"
+	// FieldTypeUint casts values to uint.
+	FieldTypeUint FieldType = "uint"
+	// FieldTypeUint64 casts values to uint64 (e.g. 64-bit snowflake IDs).
+	FieldTypeUint64 FieldType = "uint64"
 	// FieldTypeFloat64 casts values to float64.
 	FieldTypeFloat64 FieldType = "float64"
-	// FieldTypeBool casts values to bool.
+	// FieldTypeBool casts values to bool using Options.BoolValues (yes/no, on/off, ... by default),
+	// with tri-state null and any.
 	FieldTypeBool FieldType = "bool"
-	// FieldTypeDate parses dates in "2006-01-02" format (UTC, midnight).
+	// FieldTypeDate filters a timestamp column by whole days: dates in "2006-01-02" format or Unix
+	// epoch seconds/milliseconds become the instant of midnight in Options.Location (default UTC).
+	// BETWEEN on a date field covers both end days in full (see Range).
 	FieldTypeDate FieldType = "date"
-	// FieldTypeTime parses timestamps in RFC3339 (e.g. "2023-01-02T15:04:05Z") or "2006-01-02 15:04:05".
+	// FieldTypeCalendarDate is a SQL DATE column: the same inputs as FieldTypeDate, cast to the
+	// calendar day itself (UTC midnight) without applying a time zone. Relative expressions and epoch
+	// values pick the day in Options.Location.
+	FieldTypeCalendarDate FieldType = "calendar_date"
+	// FieldTypeTime parses timestamps in RFC3339 with optional fractional seconds
+	// (e.g. "2023-01-02T15:04:05.123+01:00"), "2006-01-02 15:04:05" (in Options.Location, default
+	// UTC) or Unix epoch seconds/milliseconds. Options.TimeLayouts overrides the layouts per field.
 	FieldTypeTime FieldType = "time"
+	// FieldTypeGeo is a virtual field over a latitude/longitude column pair or a point column
+	// (Options.GeoFields), filtered with the near and bbox operators and sortable by distance.
+	FieldTypeGeo FieldType = "geo"
 )
 
 // ValueCaster casts and normalizes values based on Options.FieldTypes.
 type ValueCaster struct {
 	fieldTypes map[string]FieldType
+	enums      map[string]*enumLookup
+
+	// loc is the location for dates and zone-less timestamps (Options.Location or the request's).
+	loc *time.Location
+	// now is Options.Clock (default time.Now), used for relative date expressions.
+	now func() time.Time
+	// layouts holds Options.TimeLayouts; fields without an entry use the defaults.
+	layouts map[string][]string
+	// bools maps lowercased spellings to true, false, sqlNull or matchAny.
+	bools map[string]interface{}
+	// text holds Options.TextNormalization keyed by field and by shadow column.
+	text map[string]TextNormalization
+	// geo holds Options.GeoFields.
+	geo map[string]GeoField
+	// maxGeoRadius is Options.MaxGeoRadius.
+	maxGeoRadius float64
 }
 
 // NewValueCaster creates a caster from options. If opts is nil, it defaults to string casting.
@@ -39,30 +74,66 @@ func NewValueCaster(opts *Options) *ValueCaster {
 	if opts != nil && opts.FieldTypes != nil {
 		ft = opts.FieldTypes
 	}
-	return &ValueCaster{fieldTypes: ft}
+	c := &ValueCaster{fieldTypes: ft, loc: time.UTC, now: time.Now}
+	if opts != nil && opts.Location != nil {
+		c.loc = opts.Location
+	}
+	if opts != nil && opts.Clock != nil {
+		c.now = opts.Clock
+	}
+	if opts != nil {
+		c.layouts = opts.TimeLayouts
+		c.geo = opts.GeoFields
+		c.maxGeoRadius = opts.MaxGeoRadius
+	}
+	if opts != nil && opts.BoolValues != nil {
+		// Invalid vocabularies are reported by NewValidatorFromOptions.
+		c.bools, _ = newBoolLookup(*opts.BoolValues)
+	}
+	if c.bools == nil {
+		c.bools, _ = newBoolLookup(DefaultBoolVocabulary())
+	}
+	if opts != nil && len(opts.TextNormalization) > 0 {
+		c.text = make(map[string]TextNormalization, 2*len(opts.TextNormalization))
+		for field, n := range opts.TextNormalization {
+			c.text[field] = n
+			if n.Column != "" {
+				c.text[n.Column] = n
+			}
+		}
+	}
+	if opts != nil && len(opts.Enums) > 0 {
+		c.enums = make(map[string]*enumLookup, len(opts.Enums))
+		for field, values := range opts.Enums {
+			// Invalid declarations are reported by NewValidatorFromOptions.
+			if e, err := newEnumLookup(field, values); err == nil {
+				c.enums[field] = e
+			}
+		}
+	}
+	return c
 }
 
 // CastFromString casts a raw query-string value for a given field into the configured type.
 // If no type is configured for the field, the value is returned as-is (string).
 func (c *ValueCaster) CastFromString(field string, raw string) (interface{}, error) {
+	if _, ok := c.geo[field]; ok {
+		return nil, fmt.Errorf("geo field %s requires the near or bbox operator", field)
+	}
+	if _, ok := c.text[field]; ok {
+		return c.normalizeText(field, raw), nil
+	}
 	t, ok := c.fieldTypes[field]
 	if !ok || t == "" || t == FieldTypeString {
 		return raw, nil
 	}
+	if e, ok := c.enums[field]; ok && t == FieldTypeEnum {
+		return e.lookup(field, raw)
+	}
 
 	switch t {
-	case FieldTypeInt:
-		v, err := strconv.Atoi(strings.TrimSpace(raw))
-		if err != nil {
-			return nil, fmt.Errorf("invalid int for %s: %q", field, raw)
-		}
-		return v, nil
-	case FieldTypeInt64:
-		v, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid int64 for %s: %q", field, raw)
-		}
-		return v, nil
+	case FieldTypeInt, FieldTypeInt64, FieldTypeUint, FieldTypeUint64:
+		return castInteger(field, t, raw)
 	case FieldTypeFloat64:
 		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
 		if err != nil {
@@ -70,55 +141,89 @@ func (c *ValueCaster) CastFromString(field string, raw string) (interface{}, err
 		}
 		return v, nil
 	case FieldTypeBool:
-		v, err := strconv.ParseBool(strings.TrimSpace(raw))
-		if err != nil {
-			return nil, fmt.Errorf("invalid bool for %s: %q", field, raw)
+		return c.castBool(field, raw)
+	case FieldTypeDate, FieldTypeCalendarDate, FieldTypeTime:
+		return c.castTime(field, t, raw)
+	default:
+		spec, ok := LookupFieldType(t)
+		if !ok {
+			return nil, fmt.Errorf("unknown field type %q for %s", t, field)
 		}
-		return v, nil
-	case FieldTypeDate:
-		tt, err := time.Parse("2006-01-02", strings.TrimSpace(raw))
+		v, err := spec.Parse(raw)
 		if err != nil {
-			return nil, fmt.Errorf("invalid date for %s: %q", field, raw)
-		}
-		return time.Date(tt.Year(), tt.Month(), tt.Day(), 0, 0, 0, 0, time.UTC), nil
-	case FieldTypeTime:
-		s := strings.TrimSpace(raw)
-		if tt, err := time.Parse(time.RFC3339, s); err == nil {
-			return tt, nil
+			return nil, fmt.Errorf("invalid %s for %s: %w", t, field, err)
 		}
-		if tt, err := time.Parse("2006-01-02 15:04:05", s); err == nil {
-			return tt, nil
-		}
-		return nil, fmt.Errorf("invalid time for %s: %q", field, raw)
-	default:
-		return raw, nil
+		return v, nil
 	}
 }
 
 // NormalizeJSONValue normalizes JSON values for a given field according to its configured FieldType.
 // It accepts common JSON types (string/number/bool) and converts them to the expected Go type.
-// If no type is configured, the value is returned unchanged.
+// If no type is configured, the value is returned unchanged, except that json.Number becomes an
+// int64, uint64 or float64.
 func (c *ValueCaster) NormalizeJSONValue(field string, v interface{}) (interface{}, error) {
+	if _, ok := c.geo[field]; ok {
+		return nil, fmt.Errorf("geo field %s requires the near or bbox operator", field)
+	}
+	if _, ok := c.text[field]; ok {
+		switch vv := v.(type) {
+		case string:
+			return c.normalizeText(field, vv), nil
+		case json.Number:
+			return c.normalizeText(field, vv.String()), nil
+		}
+	}
 	t, ok := c.fieldTypes[field]
-	if !ok || t == "" || t == FieldTypeString {
+	if n, isNumber := v.(json.Number); isNumber && (!ok || t == "") {
+		return jsonNumberValue(n), nil
+	}
+	if !ok || t == "" {
 		return v, nil
 	}
+	if t == FieldTypeString {
+		if n, isNumber := v.(json.Number); isNumber {
+			return n.String(), nil
+		}
+		return v, nil
+	}
+	if e, ok := c.enums[field]; ok && t == FieldTypeEnum {
+		switch vv := v.(type) {
+		case string:
+			return e.lookup(field, vv)
+		case nil, []interface{}, map[string]interface{}:
+			return nil, fmt.Errorf("invalid enum value for %s: %T", field, v)
+		default:
+			return e.lookup(field, fmt.Sprint(vv))
+		}
+	}
 
 	switch t {
-	case FieldTypeInt:
-		return normalizeToInt(field, v)
-	case FieldTypeInt64:
-		return normalizeToInt64(field, v)
+	case FieldTypeInt, FieldTypeInt64, FieldTypeUint, FieldTypeUint64:
+		return normalizeInteger(field, t, v)
 	case FieldTypeFloat64:
 		return normalizeToFloat64(field, v)
 	case FieldTypeBool:
-		return normalizeToBool(field, v)
+		return c.normalizeBool(field, v)
 	case FieldTypeDate:
 		switch vv := v.(type) {
 		case string:
 			return c.CastFromString(field, vv)
 		case time.Time:
-			return time.Date(vv.Year(), vv.Month(), vv.Day(), 0, 0, 0, 0, time.UTC), nil
+			vv = vv.In(c.loc)
+			return time.Date(vv.Year(), vv.Month(), vv.Day(), 0, 0, 0, 0, c.loc).UTC(), nil
+		case json.Number, float64, int, int64:
+			return c.castEpochJSON(field, vv)
+		default:
+			return nil, fmt.Errorf("invalid date value for %s: %T", field, v)
+		}
+	case FieldTypeCalendarDate:
+		switch vv := v.(type) {
+		case string:
+			return c.CastFromString(field, vv)
+		case time.Time:
+			return calendarDay(vv, vv.Location()), nil
+		case json.Number, float64, int, int64:
+			return c.castEpochJSON(field, vv)
 		default:
 			return nil, fmt.Errorf("invalid date value for %s: %T", field, v)
 		}
@@ -127,51 +232,59 @@ func (c *ValueCaster) NormalizeJSONValue(field string, v interface{}) (interface
 		case string:
 			return c.CastFromString(field, vv)
 		case time.Time:
-			return vv, nil
+			return vv.UTC(), nil
+		case json.Number, float64, int, int64:
+			return c.castEpochJSON(field, vv)
 		default:
 			return nil, fmt.Errorf("invalid time value for %s: %T", field, v)
 		}
 	default:
-		return v, nil
-	}
-}
-
-func normalizeToInt(field string, v interface{}) (interface{}, error) {
-	switch vv := v.(type) {
-	case float64:
-		return int(vv), nil
-	case int:
-		return vv, nil
-	case int64:
-		return int(vv), nil
-	case string:
-		i, err := strconv.Atoi(strings.TrimSpace(vv))
+		spec, ok := LookupFieldType(t)
+		if !ok {
+			return nil, fmt.Errorf("unknown field type %q for %s", t, field)
+		}
+		if spec.Normalize == nil {
+			if s, ok := v.(string); ok {
+				return c.CastFromString(field, s)
+			}
+			return nil, fmt.Errorf("invalid %s value for %s: %T", t, field, v)
+		}
+		nv, err := spec.Normalize(v)
 		if err != nil {
-			return nil, fmt.Errorf("invalid int for %s: %q", field, vv)
+			return nil, fmt.Errorf("invalid %s for %s: %w", t, field, err)
 		}
-		return i, nil
-	default:
-		return nil, fmt.Errorf("invalid int value for %s: %T", field, v)
+		return nv, nil
 	}
 }
 
-func normalizeToInt64(field string, v interface{}) (interface{}, error) {
-	switch vv := v.(type) {
-	case float64:
-		return int64(vv), nil
-	case int:
-		return int64(vv), nil
-	case int64:
-		return vv, nil
-	case string:
-		i, err := strconv.ParseInt(strings.TrimSpace(vv), 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid int64 for %s: %q", field, vv)
+// betweenValue returns the BETWEEN value for a cast [lo, hi] pair: a half-open Range ending at
+// the local midnight after hi for date fields (the next day for calendar dates), the pair itself
+// otherwise. Period bounds
+// ("2024-03") contribute their start (lo) or end (hi).
+func (c *ValueCaster) betweenValue(field string, lo, hi interface{}) interface{} {
+	if r, ok := lo.(Range); ok {
+		lo = r.From
+	}
+	switch end := hi.(type) {
+	case Range:
+		return Range{From: lo, To: end.To}
+	case time.Time:
+		switch c.fieldTypes[field] {
+		case FieldTypeDate:
+			// Add the day in the local zone so the bound stays a local midnight across DST changes.
+			return Range{From: lo, To: end.In(c.loc).AddDate(0, 0, 1).UTC()}
+		case FieldTypeCalendarDate:
+			return Range{From: lo, To: end.AddDate(0, 0, 1)}
 		}
-		return i, nil
-	default:
-		return nil, fmt.Errorf("invalid int64 value for %s: %T", field, v)
 	}
+	return []interface{}{lo, hi}
+}
+
+// inLocation returns a copy of c casting dates and zone-less timestamps in loc.
+func (c *ValueCaster) inLocation(loc *time.Location) *ValueCaster {
+	out := *c
+	out.loc = loc
+	return &out
 }
 
 func normalizeToFloat64(field string, v interface{}) (interface{}, error) {
@@ -182,6 +295,12 @@ func normalizeToFloat64(field string, v interface{}) (interface{}, error) {
 		return float64(vv), nil
 	case int64:
 		return float64(vv), nil
+	case json.Number:
+		f, err := strconv.ParseFloat(vv.String(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float64 for %s: %s", field, vv)
+		}
+		return f, nil
 	case string:
 		f, err := strconv.ParseFloat(strings.TrimSpace(vv), 64)
 		if err != nil {
@@ -193,16 +312,16 @@ func normalizeToFloat64(field string, v interface{}) (interface{}, error) {
 	}
 }
 
-func normalizeToBool(field string, v interface{}) (interface{}, error) {
+func (c *ValueCaster) normalizeBool(field string, v interface{}) (interface{}, error) {
 	switch vv := v.(type) {
 	case bool:
 		return vv, nil
+	case nil:
+		return sqlNull{}, nil
 	case string:
-		b, err := strconv.ParseBool(strings.TrimSpace(vv))
-		if err != nil {
-			return nil, fmt.Errorf("invalid bool for %s: %q", field, vv)
-		}
-		return b, nil
+		return c.castBool(field, vv)
+	case json.Number:
+		return c.castBool(field, vv.String())
 	default:
 		return nil, fmt.Errorf("invalid bool value for %s: %T", field, v)
 	}
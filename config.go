@@ -0,0 +1,528 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// OptionsConfig is the serializable form of Options, loadable from JSON or YAML.
+//
+// Example (YAML):
+//
+//	allowed_fields: [name, status, created_at]
+//	sortable_fields: [name, created_at]
+//	field_types:
+//	  created_at: date
+//	field_operators:
+//	  status: [eq, in]
+//	field_aliases:
+//	  createdAt: created_at
+//	max_limit: 100
+//
+// Use Build to validate it against a GORM model and produce Options.
+type OptionsConfig struct {
+	AllowedFields      []string                     `json:"allowed_fields" yaml:"allowed_fields"`
+	SortableFields     []string                     `json:"sortable_fields,omitempty" yaml:"sortable_fields,omitempty"`
+	SelectableFields   []string                     `json:"selectable_fields,omitempty" yaml:"selectable_fields,omitempty"`
+	GroupableFields    []string                     `json:"groupable_fields,omitempty" yaml:"groupable_fields,omitempty"`
+	AggregatableFields []string                     `json:"aggregatable_fields,omitempty" yaml:"aggregatable_fields,omitempty"`
+	QuickSearchFields  []string                     `json:"quick_search_fields,omitempty" yaml:"quick_search_fields,omitempty"`
+	FieldTypes         map[string]FieldType         `json:"field_types,omitempty" yaml:"field_types,omitempty"`
+	FieldOperators     map[string][]string          `json:"field_operators,omitempty" yaml:"field_operators,omitempty"`
+	FieldAliases       map[string]string            `json:"field_aliases,omitempty" yaml:"field_aliases,omitempty"`
+	Includes           map[string]IncludeConfig     `json:"includes,omitempty" yaml:"includes,omitempty"`
+	Redactions         map[string]RedactionConfig   `json:"redactions,omitempty" yaml:"redactions,omitempty"`
+	Enums              map[string]EnumConfig        `json:"enums,omitempty" yaml:"enums,omitempty"`
+	TimeLayouts        map[string][]string          `json:"time_layouts,omitempty" yaml:"time_layouts,omitempty"`
+	BoolValues         *BoolVocabulary              `json:"bool_values,omitempty" yaml:"bool_values,omitempty"`
+	TextNormalization  map[string]TextNormalization `json:"text_normalization,omitempty" yaml:"text_normalization,omitempty"`
+	GeoFields          map[string]GeoField          `json:"geo_fields,omitempty" yaml:"geo_fields,omitempty"`
+
+	// MaxGeoRadius is a near radius such as "1000km" (m, km or mi; bare numbers are meters).
+	MaxGeoRadius string `json:"max_geo_radius,omitempty" yaml:"max_geo_radius,omitempty"`
+
+	// Location is an IANA time zone name, e.g. "Asia/Tehran".
+	Location        string `json:"location,omitempty" yaml:"location,omitempty"`
+	RequestTimezone bool   `json:"request_timezone,omitempty" yaml:"request_timezone,omitempty"`
+
+	// StatementTimeout is a Go duration string, e.g. "5s".
+	StatementTimeout string `json:"statement_timeout,omitempty" yaml:"statement_timeout,omitempty"`
+
+	// StrictJSON defaults to true when omitted.
+	StrictJSON *bool `json:"strict_json,omitempty" yaml:"strict_json,omitempty"`
+	MaxLimit   int   `json:"max_limit,omitempty" yaml:"max_limit,omitempty"`
+
+	AllowExplain bool `json:"allow_explain,omitempty" yaml:"allow_explain,omitempty"`
+}
+
+// IncludeConfig is the serializable form of Include.
+type IncludeConfig struct {
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Limit  int      `json:"limit,omitempty" yaml:"limit,omitempty"`
+}
+
+// RedactionConfig is the serializable form of Redaction. Hash keys are secrets and are not
+// loaded from config; set Redaction.Key on the built Options instead.
+type RedactionConfig struct {
+	Mode       RedactMode `json:"mode" yaml:"mode"`
+	KeepPrefix int        `json:"keep_prefix,omitempty" yaml:"keep_prefix,omitempty"`
+	KeepSuffix int        `json:"keep_suffix,omitempty" yaml:"keep_suffix,omitempty"`
+}
+
+// EnumConfig is the serializable form of an Options.Enums entry: either a list of labels stored
+// as-is (`[active, inactive]`) or a label → stored value mapping (`{active: 1, inactive: 0}`).
+type EnumConfig map[string]interface{}
+
+// UnmarshalJSON accepts a label list or a label → value object. Integral numbers decode as int64.
+func (e *EnumConfig) UnmarshalJSON(data []byte) error {
+	var labels []string
+	if err := json.Unmarshal(data, &labels); err == nil {
+		*e = enumConfigFromLabels(labels)
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var values map[string]interface{}
+	if err := dec.Decode(&values); err != nil {
+		return fmt.Errorf("enum must be a list of labels or a label to value object: %w", err)
+	}
+	for label, v := range values {
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				values[label] = i
+			} else if f, err := n.Float64(); err == nil {
+				values[label] = f
+			}
+		}
+	}
+	*e = values
+	return nil
+}
+
+// UnmarshalYAML accepts a label list or a label → value mapping.
+func (e *EnumConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var labels []string
+		if err := node.Decode(&labels); err != nil {
+			return err
+		}
+		*e = enumConfigFromLabels(labels)
+		return nil
+	}
+	var values map[string]interface{}
+	if err := node.Decode(&values); err != nil {
+		return fmt.Errorf("enum must be a list of labels or a label to value mapping: %w", err)
+	}
+	*e = values
+	return nil
+}
+
+func enumConfigFromLabels(labels []string) EnumConfig {
+	values := make(EnumConfig, len(labels))
+	for _, label := range labels {
+		values[label] = label
+	}
+	return values
+}
+
+// ParseOptionsJSON decodes an OptionsConfig from JSON. Unknown keys are errors.
+func ParseOptionsJSON(data []byte) (*OptionsConfig, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg OptionsConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid options json: %w", err)
+	}
+	return &cfg, nil
+}
+
+// ParseOptionsYAML decodes an OptionsConfig from YAML. Unknown keys are errors.
+func ParseOptionsYAML(data []byte) (*OptionsConfig, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var cfg OptionsConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid options yaml: %w", err)
+	}
+	return &cfg, nil
+}
+
+// LoadOptionsFile reads an OptionsConfig from a .json, .yaml or .yml file.
+func LoadOptionsFile(path string) (*OptionsConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParseOptionsJSON(data)
+	case ".yaml", ".yml":
+		return ParseOptionsYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported options file extension: %q", path)
+	}
+}
+
+// ConfigFromOptions returns the serializable form of opts (lists are sorted for stable output).
+func ConfigFromOptions(opts *Options) *OptionsConfig {
+	if opts == nil {
+		return nil
+	}
+	strict := opts.StrictJSON
+	cfg := &OptionsConfig{
+		AllowedFields:      sortedFields(opts.AllowedFields),
+		SelectableFields:   sortedFields(opts.SelectableFields),
+		GroupableFields:    sortedFields(opts.GroupableFields),
+		AggregatableFields: sortedFields(opts.AggregatableFields),
+		QuickSearchFields:  opts.QuickSearchFields,
+		FieldTypes:         opts.FieldTypes,
+		FieldOperators:     opts.FieldOperators,
+		FieldAliases:       opts.FieldAliases,
+		StrictJSON:         &strict,
+		MaxLimit:           opts.MaxLimit,
+		AllowExplain:       opts.AllowExplain,
+		RequestTimezone:    opts.RequestTimezone,
+		TimeLayouts:        opts.TimeLayouts,
+		TextNormalization:  opts.TextNormalization,
+		GeoFields:          opts.GeoFields,
+	}
+	if opts.BoolValues != nil {
+		b := opts.BoolValues.clone()
+		cfg.BoolValues = &b
+	}
+	if opts.Location != nil && opts.Location != time.UTC {
+		cfg.Location = opts.Location.String()
+	}
+	if opts.SortableFields != nil {
+		cfg.SortableFields = sortedFields(opts.SortableFields)
+		if cfg.SortableFields == nil {
+			cfg.SortableFields = []string{}
+		}
+	}
+	if len(opts.Includes) > 0 {
+		cfg.Includes = make(map[string]IncludeConfig, len(opts.Includes))
+		for name, inc := range opts.Includes {
+			cfg.Includes[name] = IncludeConfig{Fields: inc.Fields, Limit: inc.Limit}
+		}
+	}
+	if len(opts.Enums) > 0 {
+		cfg.Enums = make(map[string]EnumConfig, len(opts.Enums))
+		for field, values := range opts.Enums {
+			cfg.Enums[field] = EnumConfig(values)
+		}
+	}
+	if opts.MaxGeoRadius > 0 {
+		cfg.MaxGeoRadius = strconv.FormatFloat(opts.MaxGeoRadius, 'f', -1, 64)
+	}
+	if opts.StatementTimeout > 0 {
+		cfg.StatementTimeout = opts.StatementTimeout.String()
+	}
+	if len(opts.Redactions) > 0 {
+		cfg.Redactions = make(map[string]RedactionConfig, len(opts.Redactions))
+		for field, r := range opts.Redactions {
+			cfg.Redactions[field] = RedactionConfig{Mode: r.Mode, KeepPrefix: r.KeepPrefix, KeepSuffix: r.KeepSuffix}
+		}
+	}
+	return cfg
+}
+
+// Build validates the config against a GORM model and returns Options.
+//
+// Every referenced column must exist on the model (by column name, optionally qualified with the
+// model's table), includes must be model relationships, and types/operators must be known.
+// Allowlisted fields without a configured type get one inferred from the model.
+func (c *OptionsConfig) Build(db *gorm.DB, model any) (*Options, error) {
+	if c == nil {
+		return nil, errors.New("options config is nil")
+	}
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if len(c.AllowedFields) == 0 {
+		return nil, errors.New("allowed_fields is required")
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse gorm model: %w", err)
+	}
+	s := stmt.Schema
+
+	var errs []error
+	checkColumns := func(key string, fields []string) {
+		for _, f := range fields {
+			if _, ok := c.GeoFields[f]; ok {
+				// Geo fields are virtual names over the columns checked below; they can only be
+				// filtered and sorted on.
+				if !geoConfigKeys[key] {
+					errs = append(errs, fmt.Errorf("%s: geo field %q cannot be used here", key, f))
+				}
+				continue
+			}
+			if lookupColumn(s, f) == nil {
+				errs = append(errs, fmt.Errorf("%s: unknown column %q on %s", key, f, s.Name))
+			}
+		}
+	}
+	checkColumns("allowed_fields", c.AllowedFields)
+	checkColumns("sortable_fields", c.SortableFields)
+	checkColumns("selectable_fields", c.SelectableFields)
+	checkColumns("groupable_fields", c.GroupableFields)
+	checkColumns("aggregatable_fields", c.AggregatableFields)
+	checkColumns("quick_search_fields", c.QuickSearchFields)
+	allowed := fieldSet(c.AllowedFields)
+	for _, f := range c.QuickSearchFields {
+		// Quick search only matches allowlisted fields; anything else would be skipped silently.
+		if _, ok := allowed[f]; !ok {
+			errs = append(errs, fmt.Errorf("quick_search_fields: %q is not in allowed_fields", f))
+		}
+	}
+
+	for _, field := range sortedKeys(c.FieldTypes) {
+		checkColumns("field_types", []string{field})
+		if !isKnownFieldType(c.FieldTypes[field]) {
+			errs = append(errs, fmt.Errorf("field_types: unknown type %q for %q", c.FieldTypes[field], field))
+		}
+	}
+	for _, field := range sortedKeys(c.FieldOperators) {
+		checkColumns("field_operators", []string{field})
+		for _, op := range c.FieldOperators[field] {
+			if _, err := ValidateOperator(op); err != nil {
+				errs = append(errs, fmt.Errorf("field_operators[%q]: %w", field, err))
+			}
+		}
+	}
+	for _, alias := range sortedKeys(c.FieldAliases) {
+		if !aliasRe.MatchString(alias) {
+			errs = append(errs, fmt.Errorf("field_aliases: invalid alias %q", alias))
+		}
+		checkColumns("field_aliases", []string{c.FieldAliases[alias]})
+	}
+	for _, name := range sortedKeys(c.Includes) {
+		root, _, _ := strings.Cut(name, ".")
+		if _, ok := s.Relationships.Relations[root]; !ok || !safeFieldRe.MatchString(name) {
+			errs = append(errs, fmt.Errorf("includes: unknown relation %q on %s", name, s.Name))
+		} else if c.Includes[name].Limit > 0 {
+			if _, err := limitedRelation(s, name); err != nil {
+				errs = append(errs, fmt.Errorf("includes: %w", err))
+			}
+		}
+	}
+	for _, field := range sortedKeys(c.Enums) {
+		checkColumns("enums", []string{field})
+		if ft, ok := c.FieldTypes[field]; ok && ft != FieldTypeEnum {
+			errs = append(errs, fmt.Errorf("enums: field %q has type %q", field, ft))
+		}
+		if _, err := newEnumLookup(field, c.Enums[field]); err != nil {
+			errs = append(errs, fmt.Errorf("enums: %w", err))
+		}
+	}
+	for _, field := range sortedKeys(c.TextNormalization) {
+		n := c.TextNormalization[field]
+		checkColumns("text_normalization", []string{field})
+		if n.Column != "" {
+			checkColumns("text_normalization", []string{n.Column})
+		}
+		if err := n.validate(field); err != nil {
+			errs = append(errs, fmt.Errorf("text_normalization: %w", err))
+		}
+	}
+	for _, field := range sortedKeys(c.GeoFields) {
+		g := c.GeoFields[field]
+		if lookupColumn(s, field) != nil {
+			errs = append(errs, fmt.Errorf("geo_fields: %q is a column of %s", field, s.Name))
+		}
+		if ft, ok := c.FieldTypes[field]; ok && ft != FieldTypeGeo {
+			errs = append(errs, fmt.Errorf("geo_fields: field %q has type %q", field, ft))
+		}
+		if err := g.validate(field); err != nil {
+			errs = append(errs, fmt.Errorf("geo_fields: %w", err))
+			continue
+		}
+		for _, col := range []string{g.Lat, g.Lng, g.Point} {
+			if col != "" && lookupColumn(s, col) == nil {
+				errs = append(errs, fmt.Errorf("geo_fields: unknown column %q on %s", col, s.Name))
+			}
+		}
+	}
+	if c.BoolValues != nil {
+		if _, err := newBoolLookup(*c.BoolValues); err != nil {
+			errs = append(errs, fmt.Errorf("bool_values: %w", err))
+		}
+	}
+	for _, field := range sortedKeys(c.TimeLayouts) {
+		checkColumns("time_layouts", []string{field})
+		if ft, ok := c.FieldTypes[field]; ok && !isTimeFieldType(ft) {
+			errs = append(errs, fmt.Errorf("time_layouts: field %q has type %q", field, ft))
+		}
+		if len(c.TimeLayouts[field]) == 0 {
+			errs = append(errs, fmt.Errorf("time_layouts: no layouts for %q", field))
+		}
+	}
+	for _, field := range sortedKeys(c.Redactions) {
+		checkColumns("redactions", []string{field})
+		r := c.Redactions[field]
+		if err := (Redaction{Mode: r.Mode, KeepPrefix: r.KeepPrefix, KeepSuffix: r.KeepSuffix}).validate(field); err != nil {
+			errs = append(errs, fmt.Errorf("redactions: %w", err))
+		}
+	}
+	if c.MaxLimit < 0 {
+		errs = append(errs, errors.New("max_limit must be >= 0"))
+	}
+	var maxRadius float64
+	if c.MaxGeoRadius != "" {
+		r, err := parseGeoRadius(c.MaxGeoRadius, 0)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("max_geo_radius: %w", err))
+		}
+		maxRadius = r
+	}
+	var timeout time.Duration
+	if c.StatementTimeout != "" {
+		d, err := time.ParseDuration(c.StatementTimeout)
+		if err != nil || d < 0 {
+			errs = append(errs, fmt.Errorf("statement_timeout: invalid duration %q", c.StatementTimeout))
+		}
+		timeout = d
+	}
+	var loc *time.Location
+	if c.Location != "" {
+		l, err := time.LoadLocation(c.Location)
+		if err != nil || strings.EqualFold(c.Location, "local") {
+			errs = append(errs, fmt.Errorf("location: unknown time zone %q", c.Location))
+		}
+		loc = l
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	opts := NewOptions(c.AllowedFields)
+	if c.SortableFields != nil {
+		opts.SortableFields = fieldSet(c.SortableFields)
+	}
+	if len(c.SelectableFields) > 0 {
+		opts.SelectableFields = fieldSet(c.SelectableFields)
+	}
+	if len(c.GroupableFields) > 0 {
+		opts.GroupableFields = fieldSet(c.GroupableFields)
+	}
+	if len(c.AggregatableFields) > 0 {
+		opts.AggregatableFields = fieldSet(c.AggregatableFields)
+	}
+	opts.QuickSearchFields = append([]string(nil), c.QuickSearchFields...)
+	for field, ops := range c.FieldOperators {
+		opts.WithFieldOperators(field, append([]string(nil), ops...)...)
+	}
+	for alias, column := range c.FieldAliases {
+		opts.WithFieldAlias(alias, column)
+	}
+	for name, inc := range c.Includes {
+		opts.WithInclude(name, Include{Fields: append([]string(nil), inc.Fields...), Limit: inc.Limit})
+	}
+	for field, layouts := range c.TimeLayouts {
+		opts.WithTimeLayouts(field, append([]string(nil), layouts...)...)
+	}
+	for field, r := range c.Redactions {
+		opts.WithRedaction(field, Redaction{Mode: r.Mode, KeepPrefix: r.KeepPrefix, KeepSuffix: r.KeepSuffix})
+	}
+	if c.StrictJSON != nil {
+		opts.StrictJSON = *c.StrictJSON
+	}
+	opts.MaxLimit = c.MaxLimit
+	opts.StatementTimeout = timeout
+	opts.MaxGeoRadius = maxRadius
+	opts.AllowExplain = c.AllowExplain
+	opts.Location = loc
+	opts.RequestTimezone = c.RequestTimezone
+	if c.BoolValues != nil {
+		opts.WithBoolValues(c.BoolValues.clone())
+	}
+	for field, n := range c.TextNormalization {
+		opts.WithTextNormalization(field, n)
+	}
+
+	for _, field := range c.AllowedFields {
+		if _, ok := c.GeoFields[field]; ok {
+			continue
+		}
+		f := lookupColumn(s, field)
+		if values, ok := inferEnumValues(f.FieldType); ok {
+			opts.WithEnumValues(field, values)
+		} else if ft, ok := inferSchemaFieldType(f); ok {
+			opts.FieldTypes[field] = ft
+		}
+	}
+	for field, ft := range c.FieldTypes {
+		opts.FieldTypes[field] = ft
+		if ft != FieldTypeEnum {
+			delete(opts.Enums, field)
+		}
+	}
+	for field, values := range c.Enums {
+		opts.WithEnumValues(field, map[string]interface{}(values))
+	}
+	for field, g := range c.GeoFields {
+		opts.WithGeoField(field, g)
+	}
+
+	// Catch what depends on the resolved (possibly inferred) field types here rather than on every
+	// request.
+	if _, err := NewValidatorFromOptions(opts); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// geoConfigKeys are the OptionsConfig keys that may name geo fields.
+var geoConfigKeys = map[string]bool{
+	"allowed_fields":  true,
+	"sortable_fields": true,
+	"field_types":     true,
+	"field_operators": true,
+	"field_aliases":   true,
+}
+
+// lookupColumn finds a schema field by column name, optionally qualified with the model's table.
+func lookupColumn(s *schema.Schema, name string) *schema.Field {
+	if table, column, ok := strings.Cut(name, "."); ok {
+		if table != s.Table {
+			return nil
+		}
+		name = column
+	}
+	return s.FieldsByDBName[name]
+}
+
+func sortedFields(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(set))
+	for f := range set {
+		out = append(out, f)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,104 @@
+package go_dbsearch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testOptionsYAML = `
+allowed_fields: [name, age, created_at]
+sortable_fields: [name]
+field_types:
+  created_at: date
+field_operators:
+  age: [eq, gt]
+field_aliases:
+  createdAt: created_at
+strict_json: false
+max_limit: 50
+`
+
+func TestOptionsConfig_BuildFromYAML(t *testing.T) {
+	cfg, err := ParseOptionsYAML([]byte(testOptionsYAML))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	opts, err := cfg.Build(openTagTestDB(t), &inferModel{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	if len(opts.AllowedFields) != 3 || opts.MaxLimit != 50 || opts.StrictJSON {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+	if _, ok := opts.SortableFields["age"]; ok {
+		t.Fatalf("age should not be sortable")
+	}
+	// Explicit type wins; the rest is inferred from the model.
+	if opts.FieldTypes["created_at"] != FieldTypeDate || opts.FieldTypes["age"] != FieldTypeInt {
+		t.Fatalf("unexpected field types: %v", opts.FieldTypes)
+	}
+	if opts.FieldAliases["createdAt"] != "created_at" || len(opts.FieldOperators["age"]) != 2 {
+		t.Fatalf("unexpected aliases/operators: %v %v", opts.FieldAliases, opts.FieldOperators)
+	}
+}
+
+func TestOptionsConfig_BuildErrors(t *testing.T) {
+	db := openTagTestDB(t)
+	cases := map[string]string{
+		`{"allowed_fields": ["nme"]}`:                                                                      "unknown column",
+		`{"allowed_fields": ["name"], "field_types": {"name": "uuidv9"}}`:                                  "unknown type",
+		`{"allowed_fields": ["name"], "field_operators": {"age": ["re"]}}`:                                 "operator is not allowed",
+		`{"allowed_fields": ["name"], "includes": {"Orders": {}}}`:                                         "unknown relation",
+		`{"allowed_fields": []}`:                                                                           "allowed_fields is required",
+		`{"allowed_fields": ["name"], "bool_values": {"true": ["x"], "false": ["X"]}}`:                     "listed twice",
+		`{"allowed_fields": ["age"], "text_normalization": {"age": {"fold_case": true}}}`:                  "field type must be",
+		`{"allowed_fields": ["name"], "time_layouts": {"name": ["2006"]}}`:                                 "field type must be",
+		`{"allowed_fields": ["name"], "field_types": {"name": "int"}, "time_layouts": {"name": ["2006"]}}`: "has type",
+		`{"allowed_fields": ["name"], "quick_search_fields": ["age"]}`:                                     "not in allowed_fields",
+	}
+	for body, want := range cases {
+		cfg, err := ParseOptionsJSON([]byte(body))
+		if err != nil {
+			t.Fatalf("%s: parse: %v", body, err)
+		}
+		if _, err := cfg.Build(db, &inferModel{}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected error containing %q, got %v", body, want, err)
+		}
+	}
+
+	if _, err := ParseOptionsJSON([]byte(`{"allowed_fieldz": ["name"]}`)); err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+	if _, err := ParseOptionsYAML([]byte("max_limitt: 1\n")); err == nil {
+		t.Fatalf("expected error for unknown yaml key")
+	}
+}
+
+func TestLoadOptionsFile_RoundTrip(t *testing.T) {
+	opts := NewOptions([]string{"name", "age"}).WithMaxLimit(10).WithSortableFields("name")
+
+	data, err := json.Marshal(ConfigFromOptions(opts))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	cfg, err := LoadOptionsFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got, err := cfg.Build(openTagTestDB(t), &inferModel{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if len(got.AllowedFields) != 2 || len(got.SortableFields) != 1 || got.MaxLimit != 10 || !got.StrictJSON {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+}
@@ -1,34 +1,79 @@
 package go_dbsearch
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
 
 // SearchQuery is the internal representation of a parsed search request.
 type SearchQuery struct {
 	Filters    []Filter
 	Sorts      []SortOption
 	Pagination Pagination
+
+	// Query is the global quick search term (see ApplyQuickSearch).
+	Query string
+
+	// Fields is the validated projection (see ValidateSelect); empty means all columns.
+	Fields []string
+
+	// Includes lists validated associations to preload (see ValidateIncludes).
+	// Includes are ignored when Fields is set, since projected rows are maps.
+	Includes []string
 }
 
 // ApplyWithOptions applies filters/sorts/pagination using per-handler Options.
 //
 // Phase-4: Options is required (AllowedFields must be set).
+// If opts.Scopes is set, use ApplyWithContext instead; ApplyWithOptions adds ErrScope to db.
 func ApplyWithOptions(db *gorm.DB, query SearchQuery, opts *Options) *gorm.DB {
+	s, err := newSnapshot(opts)
+	if err != nil {
+		// Without a valid allowlist, sorts are skipped and quick search matches nothing.
+		s = &Snapshot{opts: opts}
+	}
+	return applySearch(db, query, s)
+}
+
+// ApplyWithContext is ApplyWithOptions with Options.Policy and Options.Scopes resolved for ctx.
+// The query is bound to ctx (db.WithContext); resolution errors are added to the returned db.
+func ApplyWithContext(ctx context.Context, db *gorm.DB, query SearchQuery, opts *Options) *gorm.DB {
+	db = db.WithContext(ctx)
+	s, err := newSnapshot(opts)
+	if err == nil {
+		s, err = s.Resolve(ctx)
+	}
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+	return applySearch(db, query, s)
+}
+
+func applySearch(db *gorm.DB, query SearchQuery, s *Snapshot) *gorm.DB {
 	// Filters already include safe field names because parsing required options+validator.
-	tx := db
+	tx := s.ApplyScopes(db)
+	opts := s.opts
 
 	for _, filter := range query.Filters {
 		tx = filter.Apply(tx)
 	}
 
+	tx = applyQuickSearch(tx, query.Query, s)
+	tx = ApplySelect(tx, query.Fields)
+	if len(query.Fields) == 0 {
+		tx = ApplyIncludes(tx, query.Includes, opts)
+	}
+
 	// Sort validation (defense-in-depth)
-	v, err := NewValidatorFromOptions(opts)
-	if err == nil {
+	if s.validator != nil {
 		for _, sort := range query.Sorts {
-			norm, err := v.ValidateSortOption(sort)
+			norm, err := s.validator.ValidateSortOption(sort)
 			if err != nil {
 				continue
 			}
-			tx = tx.Order(norm.Field + " " + norm.Direction)
+			tx = s.validator.orderBy(tx, norm)
 		}
 	}
 
@@ -0,0 +1,74 @@
+package go_dbsearch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidEnumValue reports a value that is not one of a FieldTypeEnum field's declared labels.
+var ErrInvalidEnumValue = errors.New("invalid enum value")
+
+// EnumValuer is implemented by Go enum types to declare their values for inference
+// (InferFieldTypesFromModel, OptionsFromModel, OptionsConfig.Build). The map is keyed by the
+// external label, with the stored value as its value:
+//
+//	func (Status) EnumValues() map[string]interface{} {
+//		return map[string]interface{}{"active": 1, "inactive": 0}
+//	}
+type EnumValuer interface {
+	EnumValues() map[string]interface{}
+}
+
+var enumValuerType = reflect.TypeOf((*EnumValuer)(nil)).Elem()
+
+// inferEnumValues returns the declared values of t (or *t) if it implements EnumValuer.
+func inferEnumValues(t reflect.Type) (map[string]interface{}, bool) {
+	if t == nil {
+		return nil, false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !reflect.PointerTo(t).Implements(enumValuerType) {
+		return nil, false
+	}
+	values := reflect.New(t).Interface().(EnumValuer).EnumValues()
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values, true
+}
+
+// enumLookup resolves labels case-insensitively.
+type enumLookup struct {
+	values map[string]interface{}
+	labels []string
+}
+
+// newEnumLookup indexes values by lowercased label. Labels differing only in case are an error.
+func newEnumLookup(field string, values map[string]interface{}) (*enumLookup, error) {
+	e := &enumLookup{values: make(map[string]interface{}, len(values))}
+	for label, stored := range values {
+		key := strings.ToLower(strings.TrimSpace(label))
+		if key == "" {
+			return nil, fmt.Errorf("Enums[%q]: empty label", field)
+		}
+		if _, dup := e.values[key]; dup {
+			return nil, fmt.Errorf("Enums[%q]: labels differ only in case: %q", field, label)
+		}
+		e.values[key] = stored
+		e.labels = append(e.labels, label)
+	}
+	sort.Strings(e.labels)
+	return e, nil
+}
+
+func (e *enumLookup) lookup(field, raw string) (interface{}, error) {
+	if v, ok := e.values[strings.ToLower(strings.TrimSpace(raw))]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w for %s: %q (valid values: %s)", ErrInvalidEnumValue, field, raw, strings.Join(e.labels, ", "))
+}
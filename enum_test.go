@@ -0,0 +1,135 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type accountStatus int
+
+func (accountStatus) EnumValues() map[string]interface{} {
+	return map[string]interface{}{"active": 1, "inactive": 0, "Suspended": 2}
+}
+
+type account struct {
+	ID     uint
+	Name   string
+	Status accountStatus
+}
+
+func setupEnumTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&account{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&[]account{{Name: "a", Status: 1}, {Name: "b", Status: 0}, {Name: "c", Status: 2}})
+	return db
+}
+
+func TestEnum_InferredAndCaseInsensitive(t *testing.T) {
+	db := setupEnumTestDB(t)
+	opts := NewOptions([]string{"name", "status"})
+	if err := InferFieldTypesFromModel(db, &account{}, opts); err != nil {
+		t.Fatalf("infer: %v", err)
+	}
+	if opts.FieldTypes["status"] != FieldTypeEnum || opts.Enums["status"]["active"] != 1 {
+		t.Fatalf("expected inferred enum, got %q %+v", opts.FieldTypes["status"], opts.Enums)
+	}
+
+	router := gin.New()
+	router.GET("/accounts", SearchHandlerWithOptions[account](db, account{}, opts))
+	router.POST("/accounts", AdvancedSearchHandlerWithOptions[account](db, account{}, opts))
+
+	get := func(target string) (int, string) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", target, nil)
+		router.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	code, body := get("/accounts?filter[status]=ACTIVE")
+	var rows []account
+	if err := json.Unmarshal([]byte(body), &rows); err != nil || code != http.StatusOK || len(rows) != 1 || rows[0].Name != "a" {
+		t.Fatalf("expected only the active account, got %d %s", code, body)
+	}
+
+	code, body = get("/accounts?filter[status:in]=suspended,inactive")
+	if err := json.Unmarshal([]byte(body), &rows); err != nil || code != http.StatusOK || len(rows) != 2 {
+		t.Fatalf("expected 2 accounts, got %d %s", code, body)
+	}
+
+	code, body = get("/accounts?filter[status]=actve")
+	if code != http.StatusBadRequest || !strings.Contains(body, "valid values: Suspended, active, inactive") {
+		t.Fatalf("expected 400 listing valid values, got %d %s", code, body)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/accounts", bytes.NewBufferString(`{"filters": {"and": [{"filter": {"field": "status", "op": "eq", "value": "actve"}}]}}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid enum value") {
+		t.Fatalf("expected 400 for JSON typo, got %d %s", w.Code, w.Body.String())
+	}
+
+	opts.StrictJSON = false
+	if code, body = get("/accounts?filter[status]=actve"); code != http.StatusOK {
+		t.Fatalf("non-strict GET should ignore the filter, got %d %s", code, body)
+	}
+}
+
+func TestEnum_DeclaredLabels(t *testing.T) {
+	opts := NewOptions([]string{"plan"}).WithEnum("plan", "free", "pro")
+	c := NewValueCaster(opts)
+	if v, err := c.NormalizeJSONValue("plan", "PRO"); err != nil || v != "pro" {
+		t.Fatalf("expected stored label, got %v %v", v, err)
+	}
+	if _, err := c.NormalizeJSONValue("plan", nil); err == nil {
+		t.Fatal("expected null to be rejected")
+	}
+
+	opts.WithEnum("tier", "Gold", "gold")
+	if _, err := NewValidatorFromOptions(opts); err == nil {
+		t.Fatal("expected labels differing only in case to be rejected")
+	}
+}
+
+func TestOptionsConfig_Enums(t *testing.T) {
+	cfg, err := ParseOptionsYAML([]byte("allowed_fields: [name, age]\nenums:\n  name: [alice, bob]\n"))
+	if err != nil {
+		t.Fatalf("parse yaml: %v", err)
+	}
+	opts, err := cfg.Build(openTagTestDB(t), &inferModel{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if opts.FieldTypes["name"] != FieldTypeEnum || opts.Enums["name"]["bob"] != "bob" {
+		t.Fatalf("unexpected enum config: %q %+v", opts.FieldTypes["name"], opts.Enums)
+	}
+
+	cfg, err = ParseOptionsJSON([]byte(`{"allowed_fields": ["age"], "enums": {"age": {"young": 20, "old": 80}}}`))
+	if err != nil {
+		t.Fatalf("parse json: %v", err)
+	}
+	if opts, err = cfg.Build(openTagTestDB(t), &inferModel{}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if opts.Enums["age"]["old"] != int64(80) {
+		t.Fatalf("expected int64 stored value, got %#v", opts.Enums["age"]["old"])
+	}
+
+	cfg.FieldTypes = map[string]FieldType{"age": FieldTypeInt}
+	if _, err := cfg.Build(openTagTestDB(t), &inferModel{}); err == nil {
+		t.Fatal("expected enum on non-enum field type to fail")
+	}
+}
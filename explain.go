@@ -0,0 +1,159 @@
+package go_dbsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrExplainDisabled reports an explain request against Options without AllowExplain.
+var ErrExplainDisabled = errors.New("explain is not enabled for this endpoint")
+
+// Explanation describes the SQL a search would run.
+type Explanation struct {
+	// SQL is the statement with placeholders and Vars its bound parameters.
+	SQL  string        `json:"sql"`
+	Vars []interface{} `json:"vars"`
+
+	// Interpolated is SQL with Vars inlined by the dialector, for reading and copy-paste only.
+	Interpolated string `json:"interpolated"`
+
+	// Plan is the output of EXPLAIN (EXPLAIN QUERY PLAN on sqlite), when requested.
+	Plan []map[string]interface{} `json:"plan,omitempty"`
+}
+
+// Explain returns the SQL and bound vars tx would run, built with a GORM dry run, without executing
+// it. With plan, it also runs the dialect's EXPLAIN for the statement. Errors already recorded on
+// tx (validation, scopes) are returned as is.
+//
+// tx must be bound to a model, e.g. ApplyWithContext(ctx, db.Model(&User{}), query, opts).
+func Explain(tx *gorm.DB, plan bool) (*Explanation, error) {
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	var rows []map[string]interface{}
+	dry := tx.Session(&gorm.Session{DryRun: true}).Find(&rows)
+	if dry.Error != nil {
+		return nil, dry.Error
+	}
+	stmt := dry.Statement
+	exp := &Explanation{
+		SQL:          stmt.SQL.String(),
+		Vars:         stmt.Vars,
+		Interpolated: tx.Dialector.Explain(stmt.SQL.String(), stmt.Vars...),
+	}
+	if exp.Vars == nil {
+		exp.Vars = []interface{}{}
+	}
+
+	if plan {
+		prefix := "EXPLAIN "
+		if dialectName(tx) == "sqlite" {
+			prefix = "EXPLAIN QUERY PLAN "
+		}
+		// exp.SQL is already bound for the dialect ($n on Postgres), so it goes to the driver as is
+		// rather than through Raw, which would re-parse it for '?' placeholders.
+		p, err := queryPlan(tx, prefix+exp.SQL, exp.Vars)
+		if err != nil {
+			return nil, fmt.Errorf("explain failed: %w", err)
+		}
+		exp.Plan = p
+	}
+	return exp, nil
+}
+
+// queryPlan runs the EXPLAIN statement on tx's connection and returns its rows as maps.
+func queryPlan(tx *gorm.DB, sql string, vars []interface{}) ([]map[string]interface{}, error) {
+	ctx := tx.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rows, err := tx.Statement.ConnPool.QueryContext(ctx, sql, vars...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var out []map[string]interface{}
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(cols))
+		for i, c := range cols {
+			if b, ok := vals[i].([]byte); ok {
+				vals[i] = string(b)
+			}
+			row[c] = vals[i]
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// ExplainAdvancedSearch validates req like AdvancedSearchHandlerWithOptions and explains the main
+// query (facet queries are not included). db must be bound to the model.
+func ExplainAdvancedSearch(ctx context.Context, db *gorm.DB, req AdvancedSearchRequest, opts *Options, plan bool) (*Explanation, error) {
+	s, err := newSnapshot(opts)
+	if err != nil {
+		return nil, err
+	}
+	if s, err = s.Resolve(ctx); err != nil {
+		return nil, err
+	}
+	fields, includes, _, err := req.prepare(s)
+	if err != nil {
+		return nil, err
+	}
+	return Explain(req.apply(db.WithContext(ctx), s, fields, includes), plan)
+}
+
+// explainMode reads the "explain" query parameter: "1"/"true" for SQL, "plan" to add the EXPLAIN
+// output. Requests without it (or with "0"/"false") are not explain requests.
+func explainMode(rc RequestContext, opts *Options) (explain, plan bool, status int, err error) {
+	mode := strings.ToLower(strings.TrimSpace(rc.Query().Get("explain")))
+	switch mode {
+	case "", "0", "false":
+		return false, false, 0, nil
+	case "1", "true", "plan":
+	default:
+		return false, false, http.StatusBadRequest, fmt.Errorf("invalid explain mode: %q", mode)
+	}
+	if !opts.AllowExplain {
+		return false, false, http.StatusForbidden, ErrExplainDisabled
+	}
+	return true, mode == "plan", 0, nil
+}
+
+// serveExplain renders the Explanation for the query built by build instead of running it.
+func serveExplain(rc RequestContext, ctx context.Context, db *gorm.DB, opts *Options, plan bool, build func(tx *gorm.DB) *gorm.DB) {
+	var exp *Explanation
+	err := runQuery(ctx, db, opts.StatementTimeout, func(tx *gorm.DB) error {
+		var err error
+		exp, err = Explain(build(tx), plan)
+		return err
+	})
+	if err != nil {
+		status := queryStatus(err)
+		if errors.Is(err, ErrScope) {
+			status = validationStatus(err)
+		}
+		rc.JSON(status, errorBody(err))
+		return
+	}
+	rc.JSON(http.StatusOK, exp)
+}
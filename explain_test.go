@@ -0,0 +1,140 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestExplain_SearchQuery(t *testing.T) {
+	db := setupTestDB(t)
+	opts := NewOptions([]string{"name", "age"}).WithFieldTypes(map[string]FieldType{"age": FieldTypeInt})
+	q, err := ParseQueryWithOptions(url.Values{"filter[age:gt]": {"20"}, "sort": {"name"}, "limit": {"5"}}, opts)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	exp, err := Explain(ApplyWithContext(context.Background(), db.Model(&TestModel{}), q, opts), true)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if !strings.Contains(exp.SQL, "age > ?") || !strings.Contains(exp.SQL, "ORDER BY name") || !strings.Contains(exp.SQL, "LIMIT") {
+		t.Fatalf("unexpected SQL: %s", exp.SQL)
+	}
+	if len(exp.Vars) == 0 || fmt.Sprint(exp.Vars[0]) != "20" {
+		t.Fatalf("unexpected vars: %#v", exp.Vars)
+	}
+	if !strings.Contains(exp.Interpolated, "age > 20") {
+		t.Fatalf("unexpected interpolated SQL: %s", exp.Interpolated)
+	}
+	if len(exp.Plan) == 0 {
+		t.Fatal("expected an EXPLAIN QUERY PLAN output")
+	}
+}
+
+func TestExplainAdvancedSearch_Scoped(t *testing.T) {
+	db := setupScopeTestDB(t)
+	opts := NewOptions([]string{"title"}).WithScope(tenantScope)
+	req := AdvancedSearchRequest{Filters: &FilterGroup{Or: []FilterGroupOrLeaf{
+		{Filter: &Filter{Field: "title", Op: "eq", Value: "a"}},
+		{Filter: &Filter{Field: "title", Op: "eq", Value: "c"}},
+	}}}
+
+	ctx := context.WithValue(context.Background(), "tenant", 7) //nolint:staticcheck
+	exp, err := ExplainAdvancedSearch(ctx, db.Model(&tenantDoc{}), req, opts, false)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if !strings.Contains(exp.Interpolated, "tenant_id = 7 AND (title = \"a\" OR title = \"c\")") {
+		t.Fatalf("expected scope outside the client OR, got %s", exp.Interpolated)
+	}
+	if exp.Plan != nil {
+		t.Fatalf("plan not requested, got %+v", exp.Plan)
+	}
+}
+
+func TestHandlers_ExplainFlag(t *testing.T) {
+	db := setupTestDB(t)
+	opts := NewOptions([]string{"name"}).WithGroupableFields("name")
+	router := gin.New()
+	router.GET("/test", SearchHandlerWithOptions[TestModel](db, TestModel{}, opts))
+	router.POST("/test", AdvancedSearchHandlerWithOptions[TestModel](db, TestModel{}, opts))
+	router.POST("/agg", AggregateHandlerWithOptions[TestModel](db, TestModel{}, opts))
+
+	do := func(method, target, body string) (int, Explanation) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		var exp Explanation
+		_ = json.Unmarshal(w.Body.Bytes(), &exp)
+		return w.Code, exp
+	}
+
+	if code, _ := do("GET", "/test?explain=1", ""); code != http.StatusForbidden {
+		t.Fatalf("explain disabled: expected 403, got %d", code)
+	}
+
+	opts.AllowExplain = true
+	code, exp := do("GET", "/test?explain=1&filter[name]=Alice", "")
+	if code != http.StatusOK || !strings.Contains(exp.SQL, "name = ?") {
+		t.Fatalf("GET explain: %d %+v", code, exp)
+	}
+	code, exp = do("POST", "/test?explain=plan", `{"filters": {"and": [{"filter": {"field": "name", "op": "eq", "value": "Bob"}}]}}`)
+	if code != http.StatusOK || len(exp.Plan) == 0 || exp.Vars[0] != "Bob" {
+		t.Fatalf("POST explain: %d %+v", code, exp)
+	}
+	code, exp = do("POST", "/agg?explain=1", `{"group_by": ["name"]}`)
+	if code != http.StatusOK || !strings.Contains(exp.SQL, "GROUP BY") {
+		t.Fatalf("aggregate explain: %d %+v", code, exp)
+	}
+	if code, _ := do("GET", "/test?explain=maybe", ""); code != http.StatusBadRequest {
+		t.Fatalf("invalid mode: expected 400, got %d", code)
+	}
+}
+
+// numberedDialector binds vars as ?1, ?2, ... (numbered, like Postgres $n) while running on sqlite.
+// Plan mode must send that SQL to the driver as is; re-parsing it for '?' corrupts it.
+type numberedDialector struct{ gorm.Dialector }
+
+func (d numberedDialector) BindVarTo(w clause.Writer, stmt *gorm.Statement, v interface{}) {
+	_, _ = w.WriteString("?" + strconv.Itoa(len(stmt.Vars)))
+}
+
+func TestExplain_PlanWithNumberedPlaceholders(t *testing.T) {
+	db, err := gorm.Open(numberedDialector{sqlite.Open(":memory:")}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&TestModel{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	opts := NewOptions([]string{"name", "age"}).WithFieldTypes(map[string]FieldType{"age": FieldTypeInt})
+	q, err := ParseQueryWithOptions(url.Values{"filter[age:gt]": {"20"}, "filter[name:eq]": {"Alice"}}, opts)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	exp, err := Explain(ApplyWithContext(context.Background(), db.Model(&TestModel{}), q, opts), true)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if !strings.Contains(exp.SQL, "?1") || !strings.Contains(exp.SQL, "?2") || len(exp.Vars) != 2 {
+		t.Fatalf("expected numbered placeholders, got %s", exp.SQL)
+	}
+	if len(exp.Plan) == 0 {
+		t.Fatal("expected an EXPLAIN QUERY PLAN output")
+	}
+}
@@ -0,0 +1,249 @@
+package go_dbsearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// defaultFacetLimit is the number of facet values returned when FacetRequest.Limit is not set.
+const defaultFacetLimit = 10
+
+// FacetRequest asks for the top-N values of Field with their counts under the current filters.
+//
+// If ExcludeOwnFilter is true, filters on Field itself are ignored while counting
+// (multi-select facet semantics: the sidebar keeps showing the other values of a selected facet).
+type FacetRequest struct {
+	Field            string `json:"field"`
+	Limit            int    `json:"limit"`
+	ExcludeOwnFilter bool   `json:"exclude_own_filter"`
+}
+
+// FacetValue is a single facet bucket.
+type FacetValue struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count"`
+}
+
+// ValidateFacets validates facet requests against Options.GroupableFields and normalizes them in-place.
+// Limit defaults to 10 and is capped by Options.MaxLimit.
+func ValidateFacets(facets []FacetRequest, opts *Options) error {
+	if len(facets) == 0 {
+		return nil
+	}
+	if opts == nil {
+		return fmt.Errorf("options is required")
+	}
+
+	v := NewValidator(opts.GroupableFields)
+	seen := make(map[string]struct{}, len(facets))
+	for i := range facets {
+		f := &facets[i]
+		f.Field = strings.TrimSpace(f.Field)
+		if err := v.ValidateField(f.Field); err != nil {
+			return fmt.Errorf("facet: %w", err)
+		}
+		if _, dup := seen[f.Field]; dup {
+			return fmt.Errorf("duplicate facet: %q", f.Field)
+		}
+		seen[f.Field] = struct{}{}
+
+		if f.Limit <= 0 {
+			f.Limit = defaultFacetLimit
+		}
+		if opts.MaxLimit > 0 && f.Limit > opts.MaxLimit {
+			f.Limit = opts.MaxLimit
+		}
+	}
+	return nil
+}
+
+// validateFacets is ValidateFacets against s. Fields withheld by Permissions are rejected with
+// ErrFieldDenied.
+func (s *Snapshot) validateFacets(facets []FacetRequest) error {
+	for _, f := range facets {
+		if err := s.deniedField(s.opts.GroupableFields, f.Field); err != nil {
+			return fmt.Errorf("facet: %w", err)
+		}
+	}
+	return ValidateFacets(facets, s.opts)
+}
+
+// ApplyFacet builds the value-count query for a single validated facet.
+//
+// filters and query are the (validated) search filters and quick search term.
+func ApplyFacet(db *gorm.DB, filters *FilterGroup, query string, facet FacetRequest, opts *Options) *gorm.DB {
+	return applyFacet(db, filters, query, facet, facetSnapshot(opts))
+}
+
+// facetSnapshot builds the snapshot for the *Options facet APIs. Without a valid allowlist the
+// quick search matches nothing, as in ApplyQuickSearch.
+func facetSnapshot(opts *Options) *Snapshot {
+	s, err := newSnapshot(opts)
+	if err != nil {
+		return &Snapshot{opts: opts}
+	}
+	return s
+}
+
+// applyFacet is ApplyFacet against a resolved snapshot, so the quick search uses the request's
+// time zone and policy.
+func applyFacet(db *gorm.DB, filters *FilterGroup, query string, facet FacetRequest, s *Snapshot) *gorm.DB {
+	if facet.ExcludeOwnFilter {
+		filters = filters.withoutField(facet.Field)
+	}
+
+	tx := db
+	if filters != nil {
+		tx = filters.Apply(tx)
+	}
+	tx = applyQuickSearch(tx, query, s)
+
+	return tx.
+		Select(facet.Field + " AS value, COUNT(*) AS count").
+		Group(facet.Field).
+		Order("count DESC").
+		Order(facet.Field + " ASC").
+		Limit(facet.Limit)
+}
+
+// RunFacets executes all facet queries concurrently and returns the buckets keyed by field.
+// Queries bound to a transaction run one after another on its connection.
+//
+// newDB must return a fresh query (e.g. func() *gorm.DB { return db.Model(&model) }) for each facet.
+func RunFacets(newDB func() *gorm.DB, filters *FilterGroup, query string, facets []FacetRequest, opts *Options) (map[string][]FacetValue, error) {
+	return runFacets(newDB, filters, query, facets, facetSnapshot(opts))
+}
+
+// runFacets is RunFacets against a resolved snapshot. When newDB returns queries bound to a
+// transaction (see runQuery), they share its connection and run one after another.
+func runFacets(newDB func() *gorm.DB, filters *FilterGroup, query string, facets []FacetRequest, s *Snapshot) (map[string][]FacetValue, error) {
+	out := make(map[string][]FacetValue, len(facets))
+	if len(facets) == 0 {
+		return out, nil
+	}
+
+	collect := func(facet FacetRequest, db *gorm.DB) ([]FacetValue, error) {
+		var rows []map[string]interface{}
+		if err := applyFacet(db, filters, query, facet, s).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		values := make([]FacetValue, 0, len(rows))
+		for _, row := range rows {
+			values = append(values, FacetValue{Value: row["value"], Count: toInt64(row["count"])})
+		}
+		return values, nil
+	}
+
+	if db := newDB(); inTransaction(db) {
+		for i, facet := range facets {
+			if i > 0 {
+				db = newDB()
+			}
+			values, err := collect(facet, db)
+			if err != nil {
+				return nil, err
+			}
+			out[facet.Field] = values
+		}
+		return out, nil
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, facet := range facets {
+		wg.Add(1)
+		go func(facet FacetRequest) {
+			defer wg.Done()
+
+			values, err := collect(facet, newDB())
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			out[facet.Field] = values
+		}(facet)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+// inTransaction reports whether db runs on a transaction's connection.
+func inTransaction(db *gorm.DB) bool {
+	_, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
+
+// withoutField returns a copy of g without top-level AND items (and the OR block)
+// that only constrain field. Filters match by the field they were requested on, so aliases and
+// shadow columns (TextNormalization.Column) are excluded too.
+func (g *FilterGroup) withoutField(field string) *FilterGroup {
+	if g == nil {
+		return nil
+	}
+	out := &FilterGroup{}
+	for _, item := range g.And {
+		if !item.onlyField(field) {
+			out.And = append(out.And, item)
+		}
+	}
+	if !leavesOnlyField(g.Or, field) {
+		out.Or = g.Or
+	}
+	return out
+}
+
+func (l FilterGroupOrLeaf) onlyField(field string) bool {
+	if l.Filter != nil {
+		return l.Filter.fieldName() == field
+	}
+	if l.Group != nil {
+		return leavesOnlyField(l.Group.And, field) && leavesOnlyField(l.Group.Or, field) &&
+			len(l.Group.And)+len(l.Group.Or) > 0
+	}
+	return false
+}
+
+func leavesOnlyField(items []FilterGroupOrLeaf, field string) bool {
+	for _, item := range items {
+		if !item.onlyField(field) {
+			return false
+		}
+	}
+	return true
+}
+
+func toInt64(v interface{}) int64 {
+	switch vv := v.(type) {
+	case int64:
+		return vv
+	case int:
+		return int64(vv)
+	case int32:
+		return int64(vv)
+	case uint64:
+		return int64(vv)
+	case float64:
+		return int64(vv)
+	case []byte:
+		n, _ := strconv.ParseInt(string(vv), 10, 64)
+		return n
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,167 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// setupFacetTestDB pins the in-memory database to one connection so concurrent facet queries
+// see the same data.
+func setupFacetTestDB(t *testing.T) *gorm.DB {
+	db := setupAggregateTestDB(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+func TestFilterGroup_WithoutField(t *testing.T) {
+	g := &FilterGroup{
+		And: []FilterGroupOrLeaf{
+			{Filter: &Filter{Field: "status", Op: "IN", Value: []interface{}{"active"}}},
+			{Filter: &Filter{Field: "amount", Op: ">", Value: 1}},
+			{Group: &FilterGroup{Or: []FilterGroupOrLeaf{
+				{Filter: &Filter{Field: "status", Op: "=", Value: "a"}},
+				{Filter: &Filter{Field: "status", Op: "=", Value: "b"}},
+			}}},
+		},
+	}
+	out := g.withoutField("status")
+	if len(out.And) != 1 || out.And[0].Filter.Field != "amount" {
+		t.Fatalf("unexpected group: %+v", out)
+	}
+	if len(g.And) != 3 {
+		t.Fatalf("original group must not be modified")
+	}
+}
+
+func TestFilterGroup_WithoutFieldResolvedName(t *testing.T) {
+	opts := aggregateTestOptions().
+		WithFieldAlias("state", "status").
+		WithTextNormalization("status", TextNormalization{FoldCase: true, Column: "status_norm"})
+	v, err := NewValidatorFromOptions(opts)
+	if err != nil {
+		t.Fatalf("validator: %v", err)
+	}
+	g := &FilterGroup{And: []FilterGroupOrLeaf{
+		{Filter: &Filter{Field: "state", Op: "eq", Value: "active"}},
+		{Filter: &Filter{Field: "amount", Op: "gt", Value: 1}},
+	}}
+	if err := v.ValidateFilterGroup(g); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if g.And[0].Filter.Field != "status_norm" {
+		t.Fatalf("expected the shadow column, got %q", g.And[0].Filter.Field)
+	}
+
+	out := g.withoutField("status")
+	if len(out.And) != 1 || out.And[0].Filter.Field != "amount" {
+		t.Fatalf("expected the aliased filter to be excluded, got %+v", out)
+	}
+}
+
+func TestAdvancedSearchHandler_Facets(t *testing.T) {
+	db := setupFacetTestDB(t)
+	opts := aggregateTestOptions()
+	router := gin.New()
+	router.POST("/orders/search", AdvancedSearchHandlerWithOptions[aggOrder](db, aggOrder{}, opts))
+
+	body := []byte(`{
+		"filters": {"and": [{"filter": {"field": "status", "op": "eq", "value": "pending"}}]},
+		"facets": [{"field": "status", "exclude_own_filter": true}]
+	}`)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/orders/search", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Results []aggOrder              `json:"results"`
+		Facets  map[string][]FacetValue `json:"facets"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("expected 1 result, got %+v", resp.Results)
+	}
+	status := resp.Facets["status"]
+	if len(status) != 2 || status[0].Value != "active" || status[0].Count != 3 || status[1].Count != 1 {
+		t.Fatalf("unexpected status facet: %+v", status)
+	}
+}
+
+func TestRunFacets_OwnFilterApplied(t *testing.T) {
+	db := setupFacetTestDB(t)
+	opts := aggregateTestOptions()
+
+	filters := &FilterGroup{And: []FilterGroupOrLeaf{{Filter: &Filter{Field: "status", Op: "=", Value: "pending"}}}}
+	facets := []FacetRequest{{Field: "status"}}
+	if err := ValidateFacets(facets, opts); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	out, err := RunFacets(func() *gorm.DB { return db.Model(&aggOrder{}) }, filters, "", facets, opts)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(out["status"]) != 1 || out["status"][0].Value != "pending" {
+		t.Fatalf("unexpected facet: %+v", out["status"])
+	}
+
+	if err := ValidateFacets([]FacetRequest{{Field: "id"}}, opts); err == nil {
+		t.Fatalf("expected error for non-groupable facet field")
+	}
+}
+
+func TestAdvancedSearchHandler_FacetsUseRequestTimezone(t *testing.T) {
+	mustLoadLocation(t, "Asia/Tehran")
+	db := setupFacetTestDB(t)
+	if err := db.AutoMigrate(&tzEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	// 01:00 on 2024-03-10 in Tehran.
+	db.Create(&tzEvent{Name: "early", At: time.Date(2024, 3, 9, 21, 30, 0, 0, time.UTC)})
+	opts := NewOptions([]string{"name", "at"}).
+		WithFieldTypes(map[string]FieldType{"at": FieldTypeTime}).
+		WithQuickSearchFields("at").
+		WithGroupableFields("name").
+		WithRequestTimezone(true)
+	router := gin.New()
+	router.POST("/events/search", AdvancedSearchHandlerWithOptions[tzEvent](db, tzEvent{}, opts))
+
+	body := []byte(`{"query": "2024-03-10T01:00:00", "facets": [{"field": "name"}]}`)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/events/search?tz=Asia/Tehran", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Results []tzEvent               `json:"results"`
+		Facets  map[string][]FacetValue `json:"facets"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("expected 1 result, got %s", w.Body.String())
+	}
+	if name := resp.Facets["name"]; len(name) != 1 || name[0].Value != "early" || name[0].Count != 1 {
+		t.Fatalf("expected facets to match the results, got %+v", resp.Facets)
+	}
+}
@@ -0,0 +1,265 @@
+package go_dbsearch
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FieldTypeUUID accepts canonical (or unhyphenated) UUIDs and normalizes them to lowercase
+	// hyphenated strings. Only eq and in are allowed.
+	FieldTypeUUID FieldType = "uuid"
+	// FieldTypeDecimal accepts exact decimal numbers (Decimal), never rounding through float64
+	// for query-string and string values.
+	FieldTypeDecimal FieldType = "decimal"
+	// FieldTypeEnum treats values as opaque labels. Only eq and in are allowed.
+	FieldTypeEnum FieldType = "enum"
+	// FieldTypeDuration parses time.Duration strings ("1h30m") or integer nanoseconds.
+	FieldTypeDuration FieldType = "duration"
+)
+
+// TypeSpec defines how a FieldType is cast, normalized, filtered and inferred.
+// Register custom types with RegisterFieldType.
+type TypeSpec struct {
+	// Parse casts a query-string value. Required.
+	Parse func(raw string) (interface{}, error)
+
+	// Normalize converts a decoded JSON value. If nil, strings are passed to Parse and other
+	// JSON values are rejected.
+	Normalize func(v interface{}) (interface{}, error)
+
+	// Operators, if non-nil, restricts the operators accepted for fields of this type
+	// (canonical or alias form). Options.FieldOperators entries take precedence.
+	Operators []string
+
+	// Match reports whether a Go type maps to this FieldType in InferFieldTypesFromModel,
+	// OptionsFromModel and OptionsConfig.Build. Optional.
+	Match func(t reflect.Type) bool
+}
+
+var fieldTypes = struct {
+	sync.RWMutex
+	specs map[FieldType]TypeSpec
+}{specs: map[FieldType]TypeSpec{}}
+
+// RegisterFieldType registers a custom FieldType. The built-in primitive types and already
+// registered names cannot be redefined.
+func RegisterFieldType(t FieldType, spec TypeSpec) error {
+	if t == "" || !aliasRe.MatchString(string(t)) {
+		return fmt.Errorf("invalid field type name: %q", t)
+	}
+	if spec.Parse == nil {
+		return fmt.Errorf("field type %q: Parse is required", t)
+	}
+	for _, op := range spec.Operators {
+		if _, err := ValidateOperator(op); err != nil {
+			return fmt.Errorf("field type %q: %w", t, err)
+		}
+	}
+	if isPrimitiveFieldType(t) {
+		return fmt.Errorf("field type %q is built in", t)
+	}
+
+	fieldTypes.Lock()
+	defer fieldTypes.Unlock()
+	if _, ok := fieldTypes.specs[t]; ok {
+		return fmt.Errorf("field type %q is already registered", t)
+	}
+	spec.Operators = cloneSlice(spec.Operators)
+	fieldTypes.specs[t] = spec
+	return nil
+}
+
+// unregisterFieldType removes a registered FieldType (used by tests to undo registrations).
+func unregisterFieldType(t FieldType) {
+	fieldTypes.Lock()
+	defer fieldTypes.Unlock()
+	delete(fieldTypes.specs, t)
+}
+
+// MustRegisterFieldType is RegisterFieldType that panics on error, for use in init functions.
+func MustRegisterFieldType(t FieldType, spec TypeSpec) {
+	if err := RegisterFieldType(t, spec); err != nil {
+		panic(err)
+	}
+}
+
+// LookupFieldType returns the spec of a registered FieldType.
+func LookupFieldType(t FieldType) (TypeSpec, bool) {
+	fieldTypes.RLock()
+	defer fieldTypes.RUnlock()
+	spec, ok := fieldTypes.specs[t]
+	return spec, ok
+}
+
+// matchFieldType returns the first registered type (by name) whose Match accepts t.
+func matchFieldType(t reflect.Type) (FieldType, bool) {
+	fieldTypes.RLock()
+	defer fieldTypes.RUnlock()
+	names := make([]string, 0, len(fieldTypes.specs))
+	for name, spec := range fieldTypes.specs {
+		if spec.Match != nil {
+			names = append(names, string(name))
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if fieldTypes.specs[FieldType(name)].Match(t) {
+			return FieldType(name), true
+		}
+	}
+	return "", false
+}
+
+func isPrimitiveFieldType(t FieldType) bool {
+	switch t {
+	case FieldTypeString, FieldTypeInt, FieldTypeInt64, FieldTypeUint, FieldTypeUint64, FieldTypeFloat64,
+		FieldTypeBool, FieldTypeDate, FieldTypeCalendarDate, FieldTypeTime, FieldTypeGeo:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTimeFieldType reports whether t is cast by castTime.
+func isTimeFieldType(t FieldType) bool {
+	return t == FieldTypeDate || t == FieldTypeCalendarDate || t == FieldTypeTime
+}
+
+func isKnownFieldType(t FieldType) bool {
+	if isPrimitiveFieldType(t) {
+		return true
+	}
+	_, ok := LookupFieldType(t)
+	return ok
+}
+
+// Decimal is an exact decimal number in canonical string form (e.g. "-12.50").
+// It is passed to the database as a string, which numeric/decimal columns compare exactly.
+type Decimal string
+
+// Value implements driver.Valuer.
+func (d Decimal) Value() (driver.Value, error) {
+	return string(d), nil
+}
+
+var (
+	uuidRe    = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+	decimalRe = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+func parseUUID(raw string) (interface{}, error) {
+	s := strings.TrimSpace(raw)
+	if !uuidRe.MatchString(s) || (strings.Contains(s, "-") && len(s) != 36) {
+		return nil, fmt.Errorf("invalid uuid: %q", raw)
+	}
+	s = strings.ToLower(strings.ReplaceAll(s, "-", ""))
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+}
+
+func parseDecimal(raw string) (interface{}, error) {
+	s := strings.TrimSpace(raw)
+	if !decimalRe.MatchString(s) {
+		return nil, fmt.Errorf("invalid decimal: %q", raw)
+	}
+	return Decimal(strings.TrimPrefix(s, "+")), nil
+}
+
+func normalizeDecimal(v interface{}) (interface{}, error) {
+	switch vv := v.(type) {
+	case string:
+		return parseDecimal(vv)
+	case json.Number:
+		return parseDecimal(vv.String())
+	case Decimal:
+		return parseDecimal(string(vv))
+	case float64:
+		// Shortest representation that round-trips, i.e. the literal the client most likely sent.
+		return Decimal(strconv.FormatFloat(vv, 'f', -1, 64)), nil
+	case int:
+		return Decimal(strconv.Itoa(vv)), nil
+	case int64:
+		return Decimal(strconv.FormatInt(vv, 10)), nil
+	default:
+		return nil, fmt.Errorf("invalid decimal value: %T", v)
+	}
+}
+
+func parseDuration(raw string) (interface{}, error) {
+	s := strings.TrimSpace(raw)
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(n), nil
+	}
+	return nil, fmt.Errorf("invalid duration: %q", raw)
+}
+
+func normalizeDuration(v interface{}) (interface{}, error) {
+	switch vv := v.(type) {
+	case string:
+		return parseDuration(vv)
+	case json.Number:
+		return parseDuration(vv.String())
+	case time.Duration:
+		return vv, nil
+	case float64:
+		if vv != float64(int64(vv)) {
+			return nil, errors.New("duration nanoseconds must be an integer")
+		}
+		return time.Duration(int64(vv)), nil
+	case int:
+		return time.Duration(vv), nil
+	case int64:
+		return time.Duration(vv), nil
+	default:
+		return nil, fmt.Errorf("invalid duration value: %T", v)
+	}
+}
+
+func init() {
+	MustRegisterFieldType(FieldTypeUUID, TypeSpec{
+		Parse:     parseUUID,
+		Operators: []string{"eq", "in"},
+		Match: func(t reflect.Type) bool {
+			// github.com/google/uuid.UUID, github.com/gofrs/uuid.UUID and similar [16]byte types.
+			return t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 &&
+				t.Name() == "UUID"
+		},
+	})
+	MustRegisterFieldType(FieldTypeDecimal, TypeSpec{
+		Parse:     parseDecimal,
+		Normalize: normalizeDecimal,
+		Operators: []string{"eq", "gt", "lt", "gte", "lte", "in", "between"},
+		Match: func(t reflect.Type) bool {
+			// github.com/shopspring/decimal.Decimal and similar named types.
+			return t.Name() == "Decimal" && t.Kind() != reflect.String
+		},
+	})
+	MustRegisterFieldType(FieldTypeEnum, TypeSpec{
+		Parse: func(raw string) (interface{}, error) {
+			return strings.TrimSpace(raw), nil
+		},
+		Operators: []string{"eq", "in"},
+	})
+	MustRegisterFieldType(FieldTypeDuration, TypeSpec{
+		Parse:     parseDuration,
+		Normalize: normalizeDuration,
+		Operators: []string{"eq", "gt", "lt", "gte", "lte", "in", "between"},
+		Match: func(t reflect.Type) bool {
+			return t == durationType
+		},
+	})
+}
@@ -0,0 +1,185 @@
+package go_dbsearch
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type UUID [16]byte
+
+type Decimal128 struct{ lo, hi uint64 }
+
+type typedModel struct {
+	ID      UUID `gorm:"type:text"`
+	Price   float64
+	Timeout time.Duration
+	Status  string
+}
+
+func TestBuiltinFieldTypes_Cast(t *testing.T) {
+	c := NewValueCaster(&Options{FieldTypes: map[string]FieldType{
+		"id":      FieldTypeUUID,
+		"price":   FieldTypeDecimal,
+		"timeout": FieldTypeDuration,
+		"status":  FieldTypeEnum,
+	}})
+
+	cases := []struct {
+		field string
+		raw   string
+		want  interface{}
+	}{
+		{"id", "6BA7B810-9DAD-11D1-80B4-00C04FD430C8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"id", "6ba7b8109dad11d180b400c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"price", "0.10", Decimal("0.10")},
+		{"price", "+12345678901234567890.123456789", Decimal("12345678901234567890.123456789")},
+		{"timeout", "1h30m", 90 * time.Minute},
+		{"timeout", "1500", time.Duration(1500)},
+		{"status", " active ", "active"},
+	}
+	for _, tc := range cases {
+		got, err := c.CastFromString(tc.field, tc.raw)
+		if err != nil || got != tc.want {
+			t.Fatalf("CastFromString(%s, %q) = %#v, %v; want %#v", tc.field, tc.raw, got, err, tc.want)
+		}
+	}
+
+	for field, raw := range map[string]string{"id": "not-a-uuid", "price": "1.2.3", "timeout": "soon"} {
+		if _, err := c.CastFromString(field, raw); err == nil {
+			t.Fatalf("expected error for %s=%q", field, raw)
+		}
+	}
+
+	if got, err := c.NormalizeJSONValue("price", 0.1); err != nil || got != Decimal("0.1") {
+		t.Fatalf("NormalizeJSONValue(price, 0.1) = %#v, %v", got, err)
+	}
+	if _, err := c.NormalizeJSONValue("id", 12.0); err == nil {
+		t.Fatal("expected non-string uuid to be rejected")
+	}
+}
+
+func TestBuiltinFieldTypes_Operators(t *testing.T) {
+	opts := NewOptions([]string{"id", "status", "price"}).WithFieldTypes(map[string]FieldType{
+		"id":     FieldTypeUUID,
+		"status": FieldTypeEnum,
+		"price":  FieldTypeDecimal,
+	}).WithFieldOperators("status", "eq", "in", "like")
+	v, err := NewValidatorFromOptions(opts)
+	if err != nil {
+		t.Fatalf("validator: %v", err)
+	}
+
+	if err := v.ValidateFilter(&Filter{Field: "id", Op: "like"}); err == nil {
+		t.Fatal("expected like to be rejected for uuid")
+	}
+	if err := v.ValidateFilter(&Filter{Field: "id", Op: "in"}); err != nil {
+		t.Fatalf("expected in to be accepted for uuid: %v", err)
+	}
+	if err := v.ValidateFilter(&Filter{Field: "price", Op: "like"}); err == nil {
+		t.Fatal("expected like to be rejected for decimal")
+	}
+	// FieldOperators take precedence over the type's defaults.
+	if err := v.ValidateFilter(&Filter{Field: "status", Op: "like"}); err != nil {
+		t.Fatalf("expected FieldOperators to override enum operators: %v", err)
+	}
+
+	opts.FieldTypes["price"] = "money"
+	if _, err := NewValidatorFromOptions(opts); err == nil || !strings.Contains(err.Error(), `unknown type "money"`) {
+		t.Fatalf("expected unknown type error, got %v", err)
+	}
+}
+
+func TestRegisterFieldType(t *testing.T) {
+	if err := RegisterFieldType(FieldTypeInt, TypeSpec{Parse: func(string) (interface{}, error) { return nil, nil }}); err == nil {
+		t.Fatal("expected primitive type redefinition to fail")
+	}
+	if err := RegisterFieldType(FieldTypeUUID, TypeSpec{Parse: func(string) (interface{}, error) { return nil, nil }}); err == nil {
+		t.Fatal("expected duplicate registration to fail")
+	}
+	if err := RegisterFieldType("nope", TypeSpec{}); err == nil {
+		t.Fatal("expected missing Parse to fail")
+	}
+
+	errOdd := errors.New("odd")
+	if err := RegisterFieldType("test_even", TypeSpec{
+		Parse: func(raw string) (interface{}, error) {
+			if len(raw)%2 != 0 {
+				return nil, errOdd
+			}
+			return strings.ToUpper(raw), nil
+		},
+		Operators: []string{"eq"},
+		Match:     func(t reflect.Type) bool { return t == reflect.TypeOf(Decimal128{}) },
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() { unregisterFieldType("test_even") })
+	if !isKnownFieldType("test_even") {
+		t.Fatal("registered type should be known")
+	}
+	if ft, ok := inferFieldTypeFromReflect(reflect.TypeOf(&Decimal128{})); !ok || ft != "test_even" {
+		t.Fatalf("expected inference via Match, got %q %v", ft, ok)
+	}
+
+	opts := NewOptions([]string{"code"}).WithFieldTypes(map[string]FieldType{"code": "test_even"})
+	q, err := ParseQueryWithOptions(url.Values{"filter[code]": {"ab"}}, opts)
+	if err != nil || len(q.Filters) != 1 || q.Filters[0].Value != "AB" {
+		t.Fatalf("unexpected parse result: %+v, %v", q.Filters, err)
+	}
+	if _, err := NewValueCaster(opts).CastFromString("code", "abc"); !errors.Is(err, errOdd) {
+		t.Fatalf("expected parser error to be wrapped, got %v", err)
+	}
+}
+
+func TestInferFieldTypes_RegisteredMatchers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	opts := NewOptions([]string{"id", "price", "timeout", "status"})
+	if err := InferFieldTypesFromModel(db, &typedModel{}, opts); err != nil {
+		t.Fatalf("infer: %v", err)
+	}
+	want := map[string]FieldType{"id": FieldTypeUUID, "price": FieldTypeFloat64, "timeout": FieldTypeDuration, "status": FieldTypeString}
+	for field, ft := range want {
+		if opts.FieldTypes[field] != ft {
+			t.Fatalf("FieldTypes[%s] = %q, want %q", field, opts.FieldTypes[field], ft)
+		}
+	}
+}
+
+type priced struct {
+	ID    uint
+	Price string `gorm:"type:numeric"`
+}
+
+func TestDecimalFilter_SQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&priced{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&[]priced{{Price: "9.99"}, {Price: "10.50"}, {Price: "100.00"}})
+
+	opts := NewOptions([]string{"price"}).WithFieldTypes(map[string]FieldType{"price": FieldTypeDecimal})
+	q, err := ParseQueryWithOptions(url.Values{"filter[price:gte]": {"10.5"}}, opts)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var rows []priced
+	if err := ApplyWithOptions(db.Model(&priced{}), q, opts).Find(&rows).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows >= 10.5, got %+v", rows)
+	}
+}
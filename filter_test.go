@@ -28,10 +28,6 @@ func setupTestDB(t *testing.T) *gorm.DB {
 }
 
 func TestFilter_Apply_Equals(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupTestDB(t)
 	f := Filter{
 		Field: "name",
@@ -46,10 +42,6 @@ func TestFilter_Apply_Equals(t *testing.T) {
 }
 
 func TestFilter_Apply_Between(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupTestDB(t)
 	f := Filter{
 		Field: "age",
@@ -64,10 +56,6 @@ func TestFilter_Apply_Between(t *testing.T) {
 }
 
 func TestFilter_Apply_Like(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupTestDB(t)
 	f := Filter{
 		Field: "email",
@@ -82,10 +70,6 @@ func TestFilter_Apply_Like(t *testing.T) {
 }
 
 func TestFilter_Apply_GreaterThan(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupTestDB(t)
 	f := Filter{
 		Field: "age",
@@ -100,10 +84,6 @@ func TestFilter_Apply_GreaterThan(t *testing.T) {
 }
 
 func TestFilter_Apply_LessThan(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupTestDB(t)
 	f := Filter{
 		Field: "age",
@@ -118,10 +98,6 @@ func TestFilter_Apply_LessThan(t *testing.T) {
 }
 
 func TestFilter_Apply_In(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupTestDB(t)
 	f := Filter{
 		Field: "name",
@@ -136,10 +112,6 @@ func TestFilter_Apply_In(t *testing.T) {
 }
 
 func TestFilter_Apply_InvalidOperator(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupTestDB(t)
 	f := Filter{
 		Field: "name",
@@ -154,10 +126,6 @@ func TestFilter_Apply_InvalidOperator(t *testing.T) {
 }
 
 func TestFilter_Apply_BetweenMalformed(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupTestDB(t)
 	f := Filter{
 		Field: "age",
@@ -16,6 +16,19 @@ type Filter struct {
 	Field string      `json:"field"`
 	Op    string      `json:"op"`
 	Value interface{} `json:"value"`
+
+	// name is the resolved field name Field was validated as, before any shadow column rewrite
+	// (see Validator.ValidateFilter).
+	name string
+}
+
+// fieldName returns the field the filter was requested on: the resolved name for validated
+// filters, Field otherwise.
+func (f Filter) fieldName() string {
+	if f.name != "" {
+		return f.name
+	}
+	return f.Field
 }
 
 // Apply applies the filter to a GORM query and returns the updated query.
@@ -28,37 +41,170 @@ func (f Filter) Apply(db *gorm.DB) *gorm.DB {
 		return db
 	}
 
-	op, ok := NormalizeOperator(f.Op)
+	return applyFilterExpr(db, f.Field, f.Op, f.Value)
+}
+
+// applyFilterExpr applies op/value against a trusted SQL expression (a validated column or an
+// expression built by this package, e.g. an aggregate in a HAVING clause).
+func applyFilterExpr(db *gorm.DB, expr, op string, value interface{}) *gorm.DB {
+	op, ok := NormalizeOperator(op)
 	if !ok {
 		return db
 	}
+	switch vv := value.(type) {
+	case matchAny:
+		return db.Where("1 = 1")
+	case sqlNull:
+		if op != "=" {
+			return db
+		}
+		return db.Where(fmt.Sprintf("%s IS NULL", expr))
+	case Range:
+		if op != "BETWEEN" {
+			return applyRangeExpr(db, expr, op, vv)
+		}
+	case GeoNear:
+		if op != "NEAR" {
+			return db
+		}
+		return applyGeoNear(db, vv)
+	case GeoBox:
+		if op != "BBOX" {
+			return db
+		}
+		return applyGeoBox(db, vv)
+	}
 
 	switch op {
 	case "=":
-		return db.Where(fmt.Sprintf("%s = ?", f.Field), f.Value)
+		return db.Where(fmt.Sprintf("%s = ?", expr), value)
 	case "LIKE":
-		return db.Where(fmt.Sprintf("%s LIKE ?", f.Field), fmt.Sprintf("%%%v%%", f.Value))
+		return db.Where(fmt.Sprintf("%s LIKE ?", expr), fmt.Sprintf("%%%v%%", value))
 	case ">":
-		return db.Where(fmt.Sprintf("%s > ?", f.Field), f.Value)
+		return db.Where(fmt.Sprintf("%s > ?", expr), value)
 	case "<":
-		return db.Where(fmt.Sprintf("%s < ?", f.Field), f.Value)
+		return db.Where(fmt.Sprintf("%s < ?", expr), value)
 	case ">=":
-		return db.Where(fmt.Sprintf("%s >= ?", f.Field), f.Value)
+		return db.Where(fmt.Sprintf("%s >= ?", expr), value)
 	case "<=":
-		return db.Where(fmt.Sprintf("%s <= ?", f.Field), f.Value)
+		return db.Where(fmt.Sprintf("%s <= ?", expr), value)
 	case "IN":
-		return db.Where(fmt.Sprintf("%s IN ?", f.Field), normalizeINValue(f.Value))
+		value = normalizeINValue(value)
+		if list, ok := value.([]interface{}); ok && needsExpandedIN(list) {
+			return applyExpandedIN(db, expr, list)
+		}
+		return db.Where(fmt.Sprintf("%s IN ?", expr), value)
 	case "BETWEEN":
-		lo, hi, ok := normalizeBetweenValue(f.Value)
+		if r, ok := value.(Range); ok {
+			return db.Where(fmt.Sprintf("%s >= ? AND %s < ?", expr, expr), r.From, r.To)
+		}
+		lo, hi, ok := normalizeBetweenValue(value)
 		if !ok {
 			return db
 		}
-		return db.Where(fmt.Sprintf("%s BETWEEN ? AND ?", f.Field), lo, hi)
+		return db.Where(fmt.Sprintf("%s BETWEEN ? AND ?", expr), lo, hi)
+	default:
+		return db
+	}
+}
+
+// Range is a half-open [From, To) value. The ValueCaster produces it for BETWEEN on date fields so
+// "between 2024-01-01,2024-01-31" covers the whole last day (To is the following local midnight),
+// and for period shorthands such as "2024-03" or "2024-W11".
+//
+// Comparisons against a Range treat it as one period: = matches inside it, > and <= compare
+// against To, >= and < against From. IN accepts a mix of Ranges and plain values.
+type Range struct {
+	From interface{}
+	To   interface{}
+}
+
+func applyRangeExpr(db *gorm.DB, expr, op string, r Range) *gorm.DB {
+	switch op {
+	case "=":
+		return db.Where(fmt.Sprintf("%s >= ? AND %s < ?", expr, expr), r.From, r.To)
+	case ">":
+		return db.Where(fmt.Sprintf("%s >= ?", expr), r.To)
+	case ">=":
+		return db.Where(fmt.Sprintf("%s >= ?", expr), r.From)
+	case "<":
+		return db.Where(fmt.Sprintf("%s < ?", expr), r.From)
+	case "<=":
+		return db.Where(fmt.Sprintf("%s < ?", expr), r.To)
 	default:
 		return db
 	}
 }
 
+// checkFilterValue reports whether applyFilterExpr can render value with the canonical op; values
+// it cannot render are dropped from client filters but must fail scopes (see Snapshot.ApplyScopes).
+func checkFilterValue(op string, value interface{}) error {
+	switch vv := value.(type) {
+	case matchAny:
+		if op == "=" {
+			return nil
+		}
+	case sqlNull:
+		if op == "=" {
+			return nil
+		}
+	case Range:
+		switch op {
+		case "=", ">", ">=", "<", "<=", "BETWEEN":
+			return nil
+		}
+	case GeoNear:
+		if op == "NEAR" {
+			return nil
+		}
+	case GeoBox:
+		if op == "BBOX" {
+			return nil
+		}
+	default:
+		switch op {
+		case "=", "LIKE", ">", "<", ">=", "<=", "IN":
+			return nil
+		case "BETWEEN":
+			if _, _, ok := normalizeBetweenValue(vv); ok {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("value %T cannot be used with %s", value, op)
+}
+
+// needsExpandedIN reports whether an IN list holds Ranges or tri-state nulls, which a plain
+// "col IN ?" cannot express.
+func needsExpandedIN(list []interface{}) bool {
+	for _, v := range list {
+		switch v.(type) {
+		case Range, sqlNull:
+			return true
+		}
+	}
+	return false
+}
+
+// applyExpandedIN renders IN over Ranges, nulls and plain values as a parenthesized OR.
+func applyExpandedIN(db *gorm.DB, expr string, list []interface{}) *gorm.DB {
+	parts := make([]string, 0, len(list))
+	args := make([]interface{}, 0, 2*len(list))
+	for _, v := range list {
+		switch vv := v.(type) {
+		case Range:
+			parts = append(parts, fmt.Sprintf("(%s >= ? AND %s < ?)", expr, expr))
+			args = append(args, vv.From, vv.To)
+		case sqlNull:
+			parts = append(parts, fmt.Sprintf("%s IS NULL", expr))
+		default:
+			parts = append(parts, fmt.Sprintf("%s = ?", expr))
+			args = append(args, v)
+		}
+	}
+	return db.Where("("+strings.Join(parts, " OR ")+")", args...)
+}
+
 func normalizeINValue(v interface{}) interface{} {
 	switch vv := v.(type) {
 	case string:
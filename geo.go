@@ -0,0 +1,501 @@
+package go_dbsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// EarthRadius is the mean Earth radius in meters used for geo distances.
+const EarthRadius = 6371008.8
+
+const deg = math.Pi / 180
+
+// GeoField maps a FieldTypeGeo field to its columns, holding WGS84 degrees: either Lat and Lng,
+// or a single Point column. Point is supported on PostgreSQL (point, x = longitude) and MySQL
+// (POINT with ST_X = longitude); other dialects need Lat and Lng.
+type GeoField struct {
+	Lat   string `json:"lat,omitempty" yaml:"lat,omitempty"`
+	Lng   string `json:"lng,omitempty" yaml:"lng,omitempty"`
+	Point string `json:"point,omitempty" yaml:"point,omitempty"`
+}
+
+func (g GeoField) validate(field string) error {
+	pair := g.Lat != "" || g.Lng != ""
+	if pair == (g.Point != "") || (pair && (g.Lat == "" || g.Lng == "")) {
+		return fmt.Errorf("geo field %q: set either lat and lng, or point", field)
+	}
+	for _, c := range []string{g.Lat, g.Lng, g.Point} {
+		if c != "" && !safeFieldRe.MatchString(c) {
+			return fmt.Errorf("geo field %q: invalid column %q", field, c)
+		}
+	}
+	return nil
+}
+
+// exprs returns the latitude and longitude SQL expressions of g for db's dialect.
+func (g GeoField) exprs(db *gorm.DB) (lat, lng string, err error) {
+	if g.Point == "" {
+		if g.Lat == "" || g.Lng == "" {
+			return "", "", errors.New("geo filter has no columns")
+		}
+		return g.Lat, g.Lng, nil
+	}
+	switch name := db.Dialector.Name(); name {
+	case "postgres":
+		return g.Point + "[1]", g.Point + "[0]", nil
+	case "mysql":
+		return "ST_Y(" + g.Point + ")", "ST_X(" + g.Point + ")", nil
+	default:
+		return "", "", fmt.Errorf("geo point column %q is not supported on %s; use lat and lng columns", g.Point, name)
+	}
+}
+
+// GeoPoint is a WGS84 coordinate in degrees.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+func (p GeoPoint) validate() error {
+	if math.IsNaN(p.Lat) || p.Lat < -90 || p.Lat > 90 {
+		return fmt.Errorf("latitude out of range: %v", p.Lat)
+	}
+	if math.IsNaN(p.Lng) || p.Lng < -180 || p.Lng > 180 {
+		return fmt.Errorf("longitude out of range: %v", p.Lng)
+	}
+	return nil
+}
+
+// GeoNear is the value of a near filter: rows within Radius meters (great-circle distance) of
+// Center. The ValueCaster produces it from "lat,lng,radius" (e.g. "35.7,51.4,5km").
+type GeoNear struct {
+	Center  GeoPoint
+	Radius  float64
+	Columns GeoField `json:"-"`
+}
+
+// GeoBox is the value of a bbox filter: rows with South <= lat <= North and West <= lng <= East.
+// West greater than East denotes a box crossing the antimeridian. The ValueCaster produces it from
+// "south,west,north,east".
+type GeoBox struct {
+	South, West, North, East float64
+	Columns                  GeoField `json:"-"`
+}
+
+// box returns the smallest lat/lng box containing the circle. Callers reject circles that contain
+// a pole, so the box never spans all longitudes.
+func (n GeoNear) box() GeoBox {
+	d := n.Radius / EarthRadius
+	dLng := math.Asin(math.Sin(d)/math.Cos(n.Center.Lat*deg)) / deg
+	return GeoBox{
+		South:   n.Center.Lat - d/deg,
+		North:   n.Center.Lat + d/deg,
+		West:    wrapLng(n.Center.Lng - dLng),
+		East:    wrapLng(n.Center.Lng + dLng),
+		Columns: n.Columns,
+	}
+}
+
+func wrapLng(lng float64) float64 {
+	switch {
+	case lng < -180:
+		return lng + 360
+	case lng > 180:
+		return lng - 360
+	default:
+		return lng
+	}
+}
+
+func applyGeoBox(db *gorm.DB, b GeoBox) *gorm.DB {
+	lat, lng, err := b.Columns.exprs(db)
+	if err != nil {
+		// Match nothing even where the error is not surfaced (e.g. inside an OR branch).
+		_ = db.AddError(err)
+		return db.Where("1 = 0")
+	}
+	db = db.Where(fmt.Sprintf("%s BETWEEN ? AND ?", lat), b.South, b.North)
+	if b.West <= b.East {
+		return db.Where(fmt.Sprintf("%s BETWEEN ? AND ?", lng), b.West, b.East)
+	}
+	return db.Where(fmt.Sprintf("(%s >= ? OR %s <= ?)", lng, lng), b.West, b.East)
+}
+
+// applyGeoNear filters on the circle's bounding box (an index-friendly prefilter), then on the
+// haversine formula
+//
+//	hav(Δlat) + cos(lat0)·cos(lat)·hav(Δlng) <= hav(radius/EarthRadius)
+//
+// PostgreSQL and MySQL evaluate it with SIN, COS and RADIANS. SQLite may lack math functions, so
+// there it uses only + - *: hav is expanded as a series in Δ and cos(lat) as a series around lat0,
+// with the coefficients computed here. The effective radius is within 0.015% of haversine up to
+// 2000 km, 0.03% at 3000 km and 0.2% at 5000 km, growing with the radius; set
+// Options.MaxGeoRadius to bound it.
+func applyGeoNear(db *gorm.DB, n GeoNear) *gorm.DB {
+	lat, lng, err := n.Columns.exprs(db)
+	if err != nil {
+		_ = db.AddError(err)
+		return db.Where("1 = 0")
+	}
+	db = applyGeoBox(db, n.box())
+
+	var e geoExpr
+	p := n.Center
+	cos0, sin0 := math.Cos(p.Lat*deg), math.Sin(p.Lat*deg)
+	half := math.Sin(n.Radius / EarthRadius / 2)
+	if name := dialectName(db); name == "postgres" || name == "mysql" {
+		// sin² is periodic in 360°, so Δlng needs no wrapping here.
+		e.sql("POWER(SIN(RADIANS(" + lat + " - ")
+		e.param(p.Lat)
+		e.sql(") / 2), 2) + ")
+		e.param(cos0)
+		e.sql(" * COS(RADIANS(" + lat + ")) * POWER(SIN(RADIANS(" + lng + " - ")
+		e.param(p.Lng)
+		e.sql(") / 2), 2) <= ")
+		e.param(half * half)
+		return db.Where(e.String(), e.vars...)
+	}
+
+	e.hav(func() { e.dLat(lat, p) })
+	// cos(lat0)·cos(lat0 + x·deg) = c0 + x·(c1 + x·(c2 + x·(c3 + x·c4))) with x = Δlat in degrees.
+	e.sql(" + ")
+	e.poly(func() { e.dLat(lat, p) }, []float64{
+		cos0 * cos0,
+		-cos0 * sin0 * deg,
+		-cos0 * cos0 * deg * deg / 2,
+		cos0 * sin0 * math.Pow(deg, 3) / 6,
+		cos0 * cos0 * math.Pow(deg, 4) / 24,
+	})
+	e.sql("*")
+	e.hav(func() { e.dLng(lng, p) })
+	e.sql(" <= ")
+	e.param(half * half)
+	return db.Where(e.String(), e.vars...)
+}
+
+// geoDistanceOrder returns an ORDER BY expression ranking rows by distance from p: the
+// equirectangular Δlat² + (cos(lat0)·Δlng)², which orders nearby rows like haversine does.
+// Constants are inlined as numeric literals because GORM cannot bind variables in ORDER BY
+// without replacing the other sort columns.
+func geoDistanceOrder(db *gorm.DB, g GeoField, p GeoPoint) (string, error) {
+	lat, lng, err := g.exprs(db)
+	if err != nil {
+		return "", err
+	}
+	e := geoExpr{inline: true}
+	cos0 := math.Cos(p.Lat * deg)
+	e.dLat(lat, p)
+	e.sql("*")
+	e.dLat(lat, p)
+	e.sql(" + ")
+	e.param(cos0 * cos0)
+	e.sql("*")
+	e.dLng(lng, p)
+	e.sql("*")
+	e.dLng(lng, p)
+	return e.String(), nil
+}
+
+// geoExpr builds arithmetic SQL with its variables in placeholder order, or with float literals
+// when inline is set.
+type geoExpr struct {
+	strings.Builder
+	vars   []interface{}
+	inline bool
+}
+
+func (e *geoExpr) sql(s string) {
+	e.WriteString(s)
+}
+
+func (e *geoExpr) param(v float64) {
+	if e.inline {
+		lit := strconv.FormatFloat(v, 'g', -1, 64)
+		if v < 0 {
+			lit = "(" + lit + ")"
+		}
+		e.WriteString(lit)
+		return
+	}
+	e.WriteString("?")
+	e.vars = append(e.vars, v)
+}
+
+func (e *geoExpr) dLat(lat string, p GeoPoint) {
+	e.sql("(" + lat + " - ")
+	e.param(p.Lat)
+	e.sql(")")
+}
+
+// dLng writes lng - p.Lng wrapped into [-180, 180].
+func (e *geoExpr) dLng(lng string, p GeoPoint) {
+	if p.Lng >= 0 {
+		e.sql("(CASE WHEN " + lng + " < ")
+		e.param(p.Lng - 180)
+		e.sql(" THEN " + lng + " + 360 ELSE " + lng + " END - ")
+	} else {
+		e.sql("(CASE WHEN " + lng + " > ")
+		e.param(p.Lng + 180)
+		e.sql(" THEN " + lng + " - 360 ELSE " + lng + " END - ")
+	}
+	e.param(p.Lng)
+	e.sql(")")
+}
+
+// poly writes c[0] + x·(c[1] + x·(...)) in Horner form.
+func (e *geoExpr) poly(x func(), c []float64) {
+	e.sql("(")
+	e.param(c[0])
+	for _, ci := range c[1:] {
+		e.sql(" + ")
+		x()
+		e.sql("*(")
+		e.param(ci)
+	}
+	e.sql(strings.Repeat(")", len(c)))
+}
+
+// hav writes hav(x·deg) = sin²(x·deg/2) ≈ x²·(deg²/4 - x²·deg⁴/48 + x⁴·deg⁶/1440), x in degrees.
+func (e *geoExpr) hav(x func()) {
+	e.sql("(")
+	x()
+	e.sql("*")
+	x()
+	e.sql(")*")
+	e.poly(func() {
+		e.sql("(")
+		x()
+		e.sql("*")
+		x()
+		e.sql(")")
+	}, []float64{deg * deg / 4, -math.Pow(deg, 4) / 48, math.Pow(deg, 6) / 1440})
+}
+
+// orderBy adds sort to tx, ordering geo fields by distance from sort.Near.
+func (v *Validator) orderBy(tx *gorm.DB, sort SortOption) *gorm.DB {
+	g, ok := v.geo[sort.Field]
+	if !ok || sort.Near == nil {
+		return tx.Order(sort.Field + " " + sort.Direction)
+	}
+	expr, err := geoDistanceOrder(tx, g, *sort.Near)
+	if err != nil {
+		_ = tx.AddError(err)
+		return tx
+	}
+	return tx.Order(expr + " " + sort.Direction)
+}
+
+// withSortCenter defaults the point of a distance sort to the center of a near filter on the same
+// field, so "filter[location:near]=...&sort=location" sorts by distance from the filter's center.
+func (v *Validator) withSortCenter(opt SortOption, filters []Filter) SortOption {
+	if opt.Near != nil {
+		return opt
+	}
+	field, err := v.resolveSortField(opt.Field)
+	if err != nil {
+		return opt
+	}
+	if _, ok := v.geo[field]; !ok {
+		return opt
+	}
+	for _, f := range filters {
+		if n, ok := f.Value.(GeoNear); ok && f.Field == field {
+			center := n.Center
+			opt.Near = &center
+			return opt
+		}
+	}
+	return opt
+}
+
+// parseSortPoint splits a query-string sort of the form "field@lat:lng".
+func parseSortPoint(s string) (string, *GeoPoint, error) {
+	field, point, ok := strings.Cut(s, "@")
+	if !ok {
+		return s, nil, nil
+	}
+	lat, lng, ok := strings.Cut(point, ":")
+	if !ok {
+		return "", nil, fmt.Errorf("invalid sort point %q: want lat:lng", point)
+	}
+	p, err := parseGeoPoint(lat, lng)
+	if err != nil {
+		return "", nil, err
+	}
+	return field, &p, nil
+}
+
+// castGeo casts a query-string value of a near ("lat,lng,radius") or bbox
+// ("south,west,north,east") filter on a geo field.
+func (c *ValueCaster) castGeo(field, op, raw string) (interface{}, error) {
+	g, ok := c.geo[field]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a geo field", field)
+	}
+	parts := splitCSV(raw)
+	var v interface{}
+	var err error
+	switch op {
+	case "NEAR":
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("near value for %s must be lat,lng,radius", field)
+		}
+		v, err = newGeoNear(g, parts[0], parts[1], parts[2], c.maxGeoRadius)
+	case "BBOX":
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("bbox value for %s must be south,west,north,east", field)
+		}
+		v, err = newGeoBox(g, parts)
+	default:
+		return nil, fmt.Errorf("operator %s is not allowed for geo field %q", op, field)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field, err)
+	}
+	return v, nil
+}
+
+// normalizeGeo converts a decoded JSON near or bbox value: the query-string form, an array
+// ([lat, lng, radius] or [south, west, north, east]) or an object with those keys.
+func (c *ValueCaster) normalizeGeo(field, op string, v interface{}) (interface{}, error) {
+	keys := []string{"lat", "lng", "radius"}
+	if op == "BBOX" {
+		keys = []string{"south", "west", "north", "east"}
+	}
+	var parts []string
+	switch vv := v.(type) {
+	case string:
+		return c.castGeo(field, op, vv)
+	case []interface{}:
+		for _, item := range vv {
+			s, ok := geoJSONString(item)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s value for %s: %T", strings.ToLower(op), field, item)
+			}
+			parts = append(parts, s)
+		}
+	case map[string]interface{}:
+		for _, k := range keys {
+			item, ok := vv[k]
+			if !ok {
+				return nil, fmt.Errorf("%s value for %s needs %q", strings.ToLower(op), field, k)
+			}
+			s, ok := geoJSONString(item)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s %s for %s: %T", strings.ToLower(op), k, field, item)
+			}
+			parts = append(parts, s)
+		}
+		if len(vv) != len(keys) {
+			return nil, fmt.Errorf("%s value for %s accepts only %s", strings.ToLower(op), field, strings.Join(keys, ", "))
+		}
+	default:
+		return nil, fmt.Errorf("invalid %s value for %s: %T", strings.ToLower(op), field, v)
+	}
+	return c.castGeo(field, op, strings.Join(parts, ","))
+}
+
+func geoJSONString(v interface{}) (string, bool) {
+	switch vv := v.(type) {
+	case string:
+		return vv, true
+	case json.Number:
+		return vv.String(), true
+	case float64:
+		return strconv.FormatFloat(vv, 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+func newGeoNear(g GeoField, lat, lng, radius string, maxRadius float64) (GeoNear, error) {
+	p, err := parseGeoPoint(lat, lng)
+	if err != nil {
+		return GeoNear{}, err
+	}
+	r, err := parseGeoRadius(radius, maxRadius)
+	if err != nil {
+		return GeoNear{}, err
+	}
+	if d := r / EarthRadius / deg; p.Lat+d >= 90 || p.Lat-d <= -90 {
+		return GeoNear{}, errors.New("near circles containing a pole are not supported")
+	}
+	return GeoNear{Center: p, Radius: r, Columns: g}, nil
+}
+
+func newGeoBox(g GeoField, parts []string) (GeoBox, error) {
+	var v [4]float64
+	for i, s := range parts {
+		f, err := parseGeoFloat(s)
+		if err != nil {
+			return GeoBox{}, err
+		}
+		v[i] = f
+	}
+	b := GeoBox{South: v[0], West: v[1], North: v[2], East: v[3], Columns: g}
+	if err := (GeoPoint{Lat: b.South, Lng: b.West}).validate(); err != nil {
+		return GeoBox{}, err
+	}
+	if err := (GeoPoint{Lat: b.North, Lng: b.East}).validate(); err != nil {
+		return GeoBox{}, err
+	}
+	if b.South > b.North {
+		return GeoBox{}, errors.New("bbox south must not exceed north")
+	}
+	return b, nil
+}
+
+func parseGeoPoint(lat, lng string) (GeoPoint, error) {
+	var p GeoPoint
+	var err error
+	if p.Lat, err = parseGeoFloat(lat); err != nil {
+		return GeoPoint{}, err
+	}
+	if p.Lng, err = parseGeoFloat(lng); err != nil {
+		return GeoPoint{}, err
+	}
+	return p, p.validate()
+}
+
+func parseGeoFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, fmt.Errorf("invalid coordinate: %q", s)
+	}
+	return f, nil
+}
+
+// geoUnits are the accepted radius units; a bare number is in meters.
+var geoUnits = []struct {
+	suffix string
+	meters float64
+}{
+	{"km", 1000},
+	{"mi", 1609.344},
+	{"m", 1},
+}
+
+// parseGeoRadius parses a radius in meters, rejecting radii above max when max > 0.
+func parseGeoRadius(s string, max float64) (float64, error) {
+	num, scale := strings.ToLower(strings.TrimSpace(s)), 1.0
+	for _, u := range geoUnits {
+		if strings.HasSuffix(num, u.suffix) {
+			num, scale = strings.TrimSpace(strings.TrimSuffix(num, u.suffix)), u.meters
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || f <= 0 {
+		return 0, fmt.Errorf("invalid radius: %q", s)
+	}
+	if r := f * scale; max <= 0 || r <= max {
+		return r, nil
+	}
+	return 0, fmt.Errorf("radius %q exceeds %gkm", s, max/1000)
+}
@@ -0,0 +1,383 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type geoStore struct {
+	ID        int
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+func setupGeoDB(t *testing.T, stores ...geoStore) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&geoStore{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(stores) > 0 {
+		if err := db.Create(&stores).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func geoOptions() *Options {
+	return NewOptions([]string{"name", "location"}).
+		WithGeoField("location", GeoField{Lat: "latitude", Lng: "longitude"})
+}
+
+// geoDestination returns the point d meters from p on the given bearing (degrees).
+func geoDestination(p GeoPoint, d, bearing float64) GeoPoint {
+	lat, lng, b, a := p.Lat*deg, p.Lng*deg, bearing*deg, d/EarthRadius
+	lat2 := math.Asin(math.Sin(lat)*math.Cos(a) + math.Cos(lat)*math.Sin(a)*math.Cos(b))
+	lng2 := lng + math.Atan2(math.Sin(b)*math.Sin(a)*math.Cos(lat), math.Cos(a)-math.Sin(lat)*math.Sin(lat2))
+	return GeoPoint{Lat: lat2 / deg, Lng: wrapLng(lng2 / deg)}
+}
+
+func searchGeo(t *testing.T, db *gorm.DB, values url.Values) []string {
+	t.Helper()
+	opts := geoOptions()
+	q, err := ParseQueryWithOptions(values, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []geoStore
+	if err := ApplyWithOptions(db.Model(&geoStore{}), q, opts).Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(rows))
+	for _, r := range rows {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestGeoNear_MatchesHaversine(t *testing.T) {
+	for _, c := range []struct {
+		center GeoPoint
+		radius string
+		meters float64
+	}{
+		{GeoPoint{Lat: 35.6892, Lng: 51.389}, "500m", 500},
+		{GeoPoint{Lat: -33.8688, Lng: 151.2093}, "50km", 50e3},
+		{GeoPoint{Lat: 64.1466, Lng: -21.9426}, "1000km", 1000e3},
+		{GeoPoint{Lat: -17.7134, Lng: 179.9}, "20mi", 20 * 1609.344},
+		{GeoPoint{Lat: 85, Lng: -179.5}, "300km", 300e3},
+	} {
+		var stores []geoStore
+		var want []string
+		for bearing := 0.0; bearing < 360; bearing += 30 {
+			for _, f := range []float64{0.999, 1.001} {
+				p := geoDestination(c.center, f*c.meters, bearing)
+				name := fmt.Sprintf("%.0f@%v", bearing, f)
+				stores = append(stores, geoStore{Name: name, Latitude: p.Lat, Longitude: p.Lng})
+				if f < 1 {
+					want = append(want, name)
+				}
+			}
+		}
+		db := setupGeoDB(t, stores...)
+
+		values := url.Values{}
+		values.Set("filter[location:near]", fmt.Sprintf("%v,%v,%s", c.center.Lat, c.center.Lng, c.radius))
+		got := searchGeo(t, db, values)
+		sort.Strings(got)
+		sort.Strings(want)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("near %+v %s:\n got %v\nwant %v", c.center, c.radius, got, want)
+		}
+	}
+}
+
+func TestGeoBox(t *testing.T) {
+	db := setupGeoDB(t,
+		geoStore{Name: "tehran", Latitude: 35.69, Longitude: 51.39},
+		geoStore{Name: "isfahan", Latitude: 32.65, Longitude: 51.67},
+		geoStore{Name: "suva", Latitude: -18.14, Longitude: 178.44},
+		geoStore{Name: "apia", Latitude: -13.83, Longitude: -171.76},
+	)
+	for _, c := range []struct {
+		box  string
+		want string
+	}{
+		{"35,51,36,52", "[tehran]"},
+		{"30,50,40,55", "[isfahan tehran]"},
+		{"-20,170,-10,-170", "[apia suva]"},
+	} {
+		values := url.Values{}
+		values.Set("filter[location:bbox]", c.box)
+		values.Set("sort", "name")
+		if got := fmt.Sprint(searchGeo(t, db, values)); got != c.want {
+			t.Fatalf("bbox %s: got %s, want %s", c.box, got, c.want)
+		}
+	}
+}
+
+func TestGeoSort(t *testing.T) {
+	db := setupGeoDB(t,
+		geoStore{Name: "far", Latitude: 35.80, Longitude: 51.40},
+		geoStore{Name: "near", Latitude: 35.70, Longitude: 51.40},
+		geoStore{Name: "mid", Latitude: 35.70, Longitude: 51.50},
+		geoStore{Name: "dateline", Latitude: 35.70, Longitude: -179.95},
+	)
+
+	values := url.Values{}
+	values.Set("sort", "location@35.69:51.39,name")
+	if got := fmt.Sprint(searchGeo(t, db, values)); got != "[near mid far dateline]" {
+		t.Fatalf("got %s", got)
+	}
+
+	values.Set("sort", "-location@35.70:179.95")
+	if got := fmt.Sprint(searchGeo(t, db, values)); got != "[far near mid dateline]" {
+		t.Fatalf("descending across the antimeridian: got %s", got)
+	}
+
+	// Without a point, a distance sort uses the near filter's center.
+	values = url.Values{}
+	values.Set("filter[location:near]", "35.69,51.39,20km")
+	values.Set("sort", "location")
+	if got := fmt.Sprint(searchGeo(t, db, values)); got != "[near mid far]" {
+		t.Fatalf("sort by near center: got %s", got)
+	}
+
+	// Without any point the sort is dropped.
+	values.Del("filter[location:near]")
+	if got := len(searchGeo(t, db, values)); got != 4 {
+		t.Fatalf("expected unsorted results, got %d rows", got)
+	}
+}
+
+func TestGeo_Validation(t *testing.T) {
+	opts := geoOptions()
+	v, err := NewValidatorFromOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []Filter{
+		{Field: "location", Op: "eq"},
+		{Field: "location", Op: "in"},
+		{Field: "name", Op: "near"},
+		{Field: "name", Op: "bbox"},
+	} {
+		if err := v.ValidateFilter(&f); err == nil {
+			t.Fatalf("expected error for %s %s", f.Field, f.Op)
+		}
+	}
+	if _, err := v.ValidateSortOption(SortOption{Field: "location", Direction: "asc"}); err == nil {
+		t.Fatal("expected error for geo sort without a point")
+	}
+	if _, err := v.ValidateSortOption(SortOption{Field: "name", Direction: "asc", Near: &GeoPoint{}}); err == nil {
+		t.Fatal("expected error for a point on a non-geo sort")
+	}
+
+	c := NewValueCaster(opts)
+	for _, raw := range []string{
+		"35.7,51.4",
+		"35.7,51.4,0",
+		"35.7,51.4,-5km",
+		"35.7,51.4,7000km",
+		"95,51.4,5km",
+		"35.7,181,5km",
+		"89.9,0,50km",
+		"35.7,51.4,5 parsecs",
+	} {
+		if _, err := c.castGeo("location", "NEAR", raw); err == nil {
+			t.Fatalf("near %q: expected error", raw)
+		}
+	}
+	if _, err := c.castGeo("location", "NEAR", "35.7,51.4,3000km"); err != nil {
+		t.Fatalf("near without MaxGeoRadius: %v", err)
+	}
+	capped := NewValueCaster(geoOptions().WithMaxGeoRadius(1000e3))
+	if _, err := capped.castGeo("location", "NEAR", "35.7,51.4,1001km"); err == nil {
+		t.Fatal("expected error above MaxGeoRadius")
+	}
+	if _, err := NewValidatorFromOptions(geoOptions().WithMaxGeoRadius(-1)); err == nil {
+		t.Fatal("expected error for a negative MaxGeoRadius")
+	}
+	for _, raw := range []string{"1,2,3", "40,50,30,55", "-91,0,0,0", "0,0,0,190"} {
+		if _, err := c.castGeo("location", "BBOX", raw); err == nil {
+			t.Fatalf("bbox %q: expected error", raw)
+		}
+	}
+	if _, err := c.CastFromString("location", "35.7"); err == nil {
+		t.Fatal("expected error casting a geo field without near or bbox")
+	}
+
+	for _, bad := range []*Options{
+		NewOptions([]string{"location"}).WithFieldTypes(map[string]FieldType{"location": FieldTypeGeo}),
+		NewOptions([]string{"location"}).WithGeoField("location", GeoField{Lat: "latitude"}),
+		NewOptions([]string{"location"}).WithGeoField("location", GeoField{Lat: "a", Lng: "b", Point: "p"}),
+		NewOptions([]string{"location"}).WithGeoField("location", GeoField{Point: "p; drop"}),
+		geoOptions().WithSelectableFields("location"),
+		geoOptions().WithGroupableFields("location"),
+	} {
+		if _, err := NewValidatorFromOptions(bad); err == nil {
+			t.Fatalf("expected error for %+v", bad.GeoFields)
+		}
+	}
+}
+
+func TestGeoNear_JSONValues(t *testing.T) {
+	c := NewValueCaster(geoOptions())
+	want := GeoNear{Center: GeoPoint{Lat: 35.7, Lng: 51.4}, Radius: 5000,
+		Columns: GeoField{Lat: "latitude", Lng: "longitude"}}
+	for _, v := range []interface{}{
+		"35.7,51.4,5km",
+		[]interface{}{json.Number("35.7"), json.Number("51.4"), "5km"},
+		map[string]interface{}{"lat": json.Number("35.7"), "lng": 51.4, "radius": json.Number("5000")},
+	} {
+		got, err := c.normalizeGeo("location", "NEAR", v)
+		if err != nil {
+			t.Fatalf("%v: %v", v, err)
+		}
+		if got != want {
+			t.Fatalf("%v: got %+v", v, got)
+		}
+	}
+	for _, v := range []interface{}{
+		map[string]interface{}{"lat": 35.7, "lng": 51.4},
+		map[string]interface{}{"lat": 35.7, "lng": 51.4, "radius": 5, "unit": "km"},
+		[]interface{}{true, 51.4, 5},
+		35.7,
+	} {
+		if _, err := c.normalizeGeo("location", "NEAR", v); err == nil {
+			t.Fatalf("%v: expected error", v)
+		}
+	}
+}
+
+func TestGeo_PointColumnUnsupportedOnSQLite(t *testing.T) {
+	db := setupGeoDB(t)
+	opts := NewOptions([]string{"location"}).WithGeoField("location", GeoField{Point: "position"})
+	q, err := ParseQueryWithOptions(url.Values{"filter[location:bbox]": {"0,0,1,1"}}, opts)
+	if err != nil || len(q.Filters) != 1 {
+		t.Fatalf("parse: %v %+v", err, q.Filters)
+	}
+	var rows []geoStore
+	if err := ApplyWithOptions(db.Model(&geoStore{}), q, opts).Find(&rows).Error; err == nil {
+		t.Fatal("expected error for a point column on sqlite")
+	}
+}
+
+func TestAdvancedSearch_Geo(t *testing.T) {
+	db := setupGeoDB(t,
+		geoStore{Name: "near", Latitude: 35.70, Longitude: 51.40},
+		geoStore{Name: "mid", Latitude: 35.70, Longitude: 51.50},
+		geoStore{Name: "out", Latitude: 36.50, Longitude: 51.40},
+	)
+	h := NewAdvancedSearchHandler[geoStore](db, geoStore{}, geoOptions())
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/search", bytes.NewBufferString(body)))
+		return w
+	}
+	w := post(`{"filters":{"and":[{"filter":{"field":"location","op":"near","value":{"lat":35.69,"lng":51.39,"radius":"20km"}}}]},
+		"sort":[{"field":"location","direction":"desc"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rows []geoStore
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Name != "mid" || rows[1].Name != "near" {
+		t.Fatalf("got %+v", rows)
+	}
+
+	w = post(`{"sort":[{"field":"location","direction":"asc","near":{"lat":36.5,"lng":51.4}}]}`)
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil || len(rows) != 3 || rows[0].Name != "out" {
+		t.Fatalf("got %d %s", w.Code, w.Body.String())
+	}
+
+	if w := post(`{"filters":{"and":[{"filter":{"field":"location","op":"near","value":"35.7,51.4,7000km"}}]}}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// postgresNamed reports itself as PostgreSQL so dialect-specific SQL can be checked in a dry run.
+type postgresNamed struct{ gorm.Dialector }
+
+func (postgresNamed) Name() string { return "postgres" }
+
+func TestGeoNear_HaversineOnPostgres(t *testing.T) {
+	db, err := gorm.Open(postgresNamed{sqlite.Open(":memory:")}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := geoOptions()
+	q, err := ParseQueryWithOptions(url.Values{"filter[location:near]": {"35.7,51.4,3000km"}}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []geoStore
+	stmt := ApplyWithOptions(db.Model(&geoStore{}), q, opts).Find(&rows).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "POWER(SIN(RADIANS(latitude - ?) / 2), 2)") ||
+		!strings.Contains(sql, "COS(RADIANS(latitude))") {
+		t.Fatalf("expected the haversine formula, got %s", sql)
+	}
+	half := math.Sin(3000e3 / EarthRadius / 2)
+	if last := stmt.Vars[len(stmt.Vars)-1]; last != half*half {
+		t.Fatalf("expected hav(radius) as the bound, got %v", last)
+	}
+}
+
+func TestOptionsConfig_GeoFields(t *testing.T) {
+	db := setupGeoDB(t)
+	cfg, err := ParseOptionsYAML([]byte(`
+allowed_fields: [name, location]
+sortable_fields: [name, location]
+geo_fields:
+  location: {lat: latitude, lng: longitude}
+max_geo_radius: 500km
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := cfg.Build(db, &geoStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.FieldTypes["location"] != FieldTypeGeo || opts.GeoFields["location"].Lat != "latitude" || opts.MaxGeoRadius != 500e3 {
+		t.Fatalf("got %+v %+v", opts.FieldTypes, opts.GeoFields)
+	}
+	if rt, err := ConfigFromOptions(opts).Build(db, &geoStore{}); err != nil || rt.MaxGeoRadius != 500e3 {
+		t.Fatalf("round trip: %v", err)
+	}
+
+	for _, key := range []string{"selectable_fields", "groupable_fields"} {
+		bad, err := ParseOptionsYAML([]byte("allowed_fields: [name, location]\n" + key + ": [location]\n" +
+			"geo_fields:\n  location: {lat: latitude, lng: longitude}\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bad.Build(db, &geoStore{}); err == nil || !strings.Contains(err.Error(), "cannot be used here") {
+			t.Fatalf("%s: expected a geo field error, got %v", key, err)
+		}
+	}
+
+	cfg.GeoFields["location"] = GeoField{Lat: "latitude", Lng: "lon"}
+	if _, err := cfg.Build(db, &geoStore{}); err == nil {
+		t.Fatal("expected error for unknown column")
+	}
+}
@@ -1,39 +1,70 @@
 package go_dbsearch
 
 import (
-	"net/http"
+	"context"
+	"net/url"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// GinHandler adapts a framework-neutral HandlerFunc to Gin.
+//
+// Policies and scopes see values set with c.Set via ctx.Value, and queries are canceled with the
+// underlying request context.
+func GinHandler(h HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h(ginRequest{c: c})
+	}
+}
+
+// ginRequest is the Gin RequestContext adapter.
+type ginRequest struct {
+	c *gin.Context
+}
+
+func (g ginRequest) Context() context.Context { return ginContext{c: g.c} }
+
+func (g ginRequest) Query() url.Values { return g.c.Request.URL.Query() }
+
+func (g ginRequest) Header(name string) string { return g.c.GetHeader(name) }
+
+func (g ginRequest) BindJSON(v interface{}) error { return decodeJSON(g.c.Request.Body, v) }
+
+func (g ginRequest) JSON(status int, v interface{}) { g.c.JSON(status, v) }
+
+// ginContext takes cancellation and deadlines from the request context and values from the
+// gin.Context first (c.Keys), then from the request context. A plain *gin.Context does not
+// propagate cancellation unless the engine sets ContextWithFallback.
+type ginContext struct {
+	c *gin.Context
+}
+
+func (g ginContext) Deadline() (time.Time, bool) { return g.c.Request.Context().Deadline() }
+
+func (g ginContext) Done() <-chan struct{} { return g.c.Request.Context().Done() }
+
+func (g ginContext) Err() error { return g.c.Request.Context().Err() }
+
+func (g ginContext) Value(key any) any {
+	if v := g.c.Value(key); v != nil {
+		return v
+	}
+	return g.c.Request.Context().Value(key)
+}
+
 // SearchHandlerWithOptions performs GET search using query-string parameters.
 //
 // Options is required (AllowedFields must be set).
 func SearchHandlerWithOptions[T any](db *gorm.DB, model T, opts *Options) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		query, err := ParseQueryWithOptions(c.Request.URL.Query(), opts)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		var results []T
-		tx := ApplyWithOptions(db.Model(&model), query, opts)
-
-		if err := tx.Find(&results).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusOK, results)
-	}
+	return GinHandler(NewSearchHandler[T](db, model, opts))
 }
 
-// AdvancedSearchRequest is the JSON payload for AdvancedSearchHandlerWithOptions.
-type AdvancedSearchRequest struct {
-	Filters    *FilterGroup `json:"filters"`
-	Sort       []SortOption `json:"sort"`
-	Pagination Pagination   `json:"pagination"`
+// SearchHandlerFromRegistry is SearchHandlerWithOptions using the snapshot registered under name,
+// looked up on every request so reloaded options take effect immediately.
+func SearchHandlerFromRegistry[T any](db *gorm.DB, model T, reg *Registry, name string) gin.HandlerFunc {
+	return GinHandler(NewSearchHandlerFromRegistry[T](db, model, reg, name))
 }
 
 // AdvancedSearchHandlerWithOptions performs POST search using JSON body.
@@ -41,79 +72,26 @@ type AdvancedSearchRequest struct {
 // Phase-4: Options is required (AllowedFields must be set).
 // If opts.FieldTypes is empty, you may call InferFieldTypesFromModel(db, model, opts) once at startup.
 func AdvancedSearchHandlerWithOptions[T any](db *gorm.DB, model T, opts *Options) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var req AdvancedSearchRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		v, err := NewValidatorFromOptions(opts)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		caster := NewValueCaster(opts)
-
-		if err := v.ValidateFilterGroup(req.Filters); err != nil {
-			if opts.StrictJSON {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-			req.Filters = nil
-		}
-
-		if err := NormalizeFilterGroupValues(req.Filters, caster); err != nil {
-			if opts.StrictJSON {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-			req.Filters = nil
-		}
-
-		for i := range req.Sort {
-			norm, err := v.ValidateSortOption(req.Sort[i])
-			if err != nil {
-				if opts.StrictJSON {
-					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-					return
-				}
-				req.Sort[i] = SortOption{}
-				continue
-			}
-			req.Sort[i] = norm
-		}
-
-		tx := db.Model(&model)
-
-		if req.Filters != nil {
-			tx = req.Filters.Apply(tx)
-		}
-
-		for _, s := range req.Sort {
-			if s.Field == "" {
-				continue
-			}
-			tx = tx.Order(s.Field + " " + s.Direction)
-		}
-
-		limit := req.Pagination.Limit
-		if opts.MaxLimit > 0 && limit > opts.MaxLimit {
-			limit = opts.MaxLimit
-		}
-		if limit > 0 {
-			tx = tx.Limit(limit)
-		}
-		if req.Pagination.Offset > 0 {
-			tx = tx.Offset(req.Pagination.Offset)
-		}
-
-		var results []T
-		if err := tx.Find(&results).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		c.JSON(http.StatusOK, results)
-	}
+	return GinHandler(NewAdvancedSearchHandler[T](db, model, opts))
+}
+
+// AdvancedSearchHandlerFromRegistry is AdvancedSearchHandlerWithOptions using the snapshot
+// registered under name, looked up on every request.
+func AdvancedSearchHandlerFromRegistry[T any](db *gorm.DB, model T, reg *Registry, name string) gin.HandlerFunc {
+	return GinHandler(NewAdvancedSearchHandlerFromRegistry[T](db, model, reg, name))
+}
+
+// AggregateHandlerWithOptions performs POST aggregation (count/sum/avg/min/max grouped by fields)
+// using the same filter language as AdvancedSearchHandlerWithOptions.
+//
+// Group-by fields must be in opts.GroupableFields and metric fields in opts.AggregatableFields.
+// The response is a JSON array of rows keyed by group-by field and metric alias.
+func AggregateHandlerWithOptions[T any](db *gorm.DB, model T, opts *Options) gin.HandlerFunc {
+	return GinHandler(NewAggregateHandler[T](db, model, opts))
+}
+
+// AggregateHandlerFromRegistry is AggregateHandlerWithOptions using the snapshot registered
+// under name, looked up on every request.
+func AggregateHandlerFromRegistry[T any](db *gorm.DB, model T, reg *Registry, name string) gin.HandlerFunc {
+	return GinHandler(NewAggregateHandlerFromRegistry[T](db, model, reg, name))
 }
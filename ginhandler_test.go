@@ -11,13 +11,10 @@ import (
 )
 
 func TestAdvancedSearchHandler(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupTestDB(t)
 	router := gin.Default()
-	router.POST("/test", AdvancedSearchHandler[TestModel](db, TestModel{}))
+	opts := NewOptions([]string{"id", "name", "email", "age"})
+	router.POST("/test", AdvancedSearchHandlerWithOptions[TestModel](db, TestModel{}, opts))
 
 	payload := AdvancedSearchRequest{
 		Filters: &FilterGroup{
@@ -23,12 +23,20 @@ type FilterGroupOrLeaf struct {
 // Security:
 //   - Validate fields/operators with Validator before calling Apply (recommended).
 func (g *FilterGroup) Apply(db *gorm.DB) *gorm.DB {
+	return g.applyWith(db, Filter.Apply)
+}
+
+// filterApplier applies a single leaf filter to a scope.
+type filterApplier func(f Filter, db *gorm.DB) *gorm.DB
+
+// applyWith applies the group using apply for every leaf filter.
+func (g *FilterGroup) applyWith(db *gorm.DB, apply filterApplier) *gorm.DB {
 	if g == nil {
 		return db
 	}
 
 	for _, item := range g.And {
-		db = applyLeafAsAnd(db, item)
+		db = applyLeafAsAnd(db, item, apply)
 	}
 
 	if len(g.Or) > 0 {
@@ -37,7 +45,7 @@ func (g *FilterGroup) Apply(db *gorm.DB) *gorm.DB {
 
 		for _, item := range g.Or {
 			branch := newScopeDB(db)
-			branch = item.applyToScope(branch)
+			branch = item.applyToScope(branch, apply)
 
 			if first {
 				orBlock = orBlock.Where(branch)
@@ -55,24 +63,24 @@ func (g *FilterGroup) Apply(db *gorm.DB) *gorm.DB {
 	return db
 }
 
-func applyLeafAsAnd(db *gorm.DB, item FilterGroupOrLeaf) *gorm.DB {
+func applyLeafAsAnd(db *gorm.DB, item FilterGroupOrLeaf, apply filterApplier) *gorm.DB {
 	if item.Filter != nil {
-		return item.Filter.Apply(db)
+		return apply(*item.Filter, db)
 	}
 	if item.Group != nil {
 		sub := newScopeDB(db)
-		sub = item.Group.Apply(sub)
+		sub = item.Group.applyWith(sub, apply)
 		return db.Where(sub)
 	}
 	return db
 }
 
-func (l FilterGroupOrLeaf) applyToScope(scope *gorm.DB) *gorm.DB {
+func (l FilterGroupOrLeaf) applyToScope(scope *gorm.DB, apply filterApplier) *gorm.DB {
 	if l.Filter != nil {
-		return l.Filter.Apply(scope)
+		return apply(*l.Filter, scope)
 	}
 	if l.Group != nil {
-		return l.Group.Apply(scope)
+		return l.Group.applyWith(scope, apply)
 	}
 	return scope
 }
@@ -28,10 +28,6 @@ func setupGroupTestDB(t *testing.T) *gorm.DB {
 }
 
 func TestFilterGroup_And(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupGroupTestDB(t)
 	group := FilterGroup{
 		And: []FilterGroupOrLeaf{
@@ -47,10 +43,6 @@ func TestFilterGroup_And(t *testing.T) {
 }
 
 func TestFilterGroup_Or(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupGroupTestDB(t)
 	group := FilterGroup{
 		Or: []FilterGroupOrLeaf{
@@ -66,10 +58,6 @@ func TestFilterGroup_Or(t *testing.T) {
 }
 
 func TestFilterGroup_Nested(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupGroupTestDB(t)
 	group := FilterGroup{
 		And: []FilterGroupOrLeaf{
@@ -90,10 +78,6 @@ func TestFilterGroup_Nested(t *testing.T) {
 }
 
 func TestFilterGroup_Empty(t *testing.T) {
-	AllowedFields["id"] = true
-	AllowedFields["name"] = true
-	AllowedFields["email"] = true
-	AllowedFields["age"] = true
 	db := setupGroupTestDB(t)
 	group := FilterGroup{}
 	var result []GroupTestModel
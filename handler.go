@@ -0,0 +1,456 @@
+package go_dbsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// RequestContext adapts a web framework's request and response to the handler core.
+//
+// Adapters are provided for net/http (HandlerFunc.ServeHTTP) and Gin (GinHandler); other
+// frameworks only need these five methods.
+type RequestContext interface {
+	// Context is passed to Options.Policy and Options.Scopes.
+	Context() context.Context
+	// Query returns the URL query parameters.
+	Query() url.Values
+	// Header returns the first value of the named request header.
+	Header(name string) string
+	// BindJSON decodes the JSON request body into v. Implementations should keep numbers as
+	// json.Number (json.Decoder.UseNumber) so large integers are not rounded through float64.
+	BindJSON(v interface{}) error
+	// JSON writes v as the JSON response with the given status.
+	JSON(status int, v interface{})
+}
+
+// HandlerFunc is a framework-neutral search handler covering the whole request lifecycle
+// (decode, validate, normalize, apply, execute, render).
+//
+// It implements http.Handler, so it can be mounted directly on net/http, chi or any router that
+// accepts http.Handler (e.g. echo.WrapHandler). Use GinHandler for Gin.
+type HandlerFunc func(rc RequestContext)
+
+// ServeHTTP serves h using the net/http adapter; policies and scopes see r.Context().
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h(httpRequest{w: w, r: r})
+}
+
+// httpRequest is the net/http RequestContext adapter.
+type httpRequest struct {
+	w http.ResponseWriter
+	r *http.Request
+}
+
+func (h httpRequest) Context() context.Context { return h.r.Context() }
+
+func (h httpRequest) Query() url.Values { return h.r.URL.Query() }
+
+func (h httpRequest) Header(name string) string { return h.r.Header.Get(name) }
+
+func (h httpRequest) BindJSON(v interface{}) error { return decodeJSON(h.r.Body, v) }
+
+func (h httpRequest) JSON(status int, v interface{}) {
+	h.w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	h.w.WriteHeader(status)
+	_ = json.NewEncoder(h.w).Encode(v)
+}
+
+// errorBody is the JSON error response shared by all adapters.
+func errorBody(err error) map[string]interface{} {
+	return map[string]interface{}{"error": err.Error()}
+}
+
+// NewSearchHandler returns the framework-neutral form of SearchHandlerWithOptions.
+func NewSearchHandler[T any](db *gorm.DB, model T, opts *Options) HandlerFunc {
+	return searchHandler[T](db, model, optionsSource(opts))
+}
+
+// NewSearchHandlerFromRegistry returns the framework-neutral form of SearchHandlerFromRegistry.
+func NewSearchHandlerFromRegistry[T any](db *gorm.DB, model T, reg *Registry, name string) HandlerFunc {
+	return searchHandler[T](db, model, reg.source(name))
+}
+
+// NewAdvancedSearchHandler returns the framework-neutral form of AdvancedSearchHandlerWithOptions.
+func NewAdvancedSearchHandler[T any](db *gorm.DB, model T, opts *Options) HandlerFunc {
+	return advancedSearchHandler[T](db, model, optionsSource(opts))
+}
+
+// NewAdvancedSearchHandlerFromRegistry returns the framework-neutral form of
+// AdvancedSearchHandlerFromRegistry.
+func NewAdvancedSearchHandlerFromRegistry[T any](db *gorm.DB, model T, reg *Registry, name string) HandlerFunc {
+	return advancedSearchHandler[T](db, model, reg.source(name))
+}
+
+// NewAggregateHandler returns the framework-neutral form of AggregateHandlerWithOptions.
+func NewAggregateHandler[T any](db *gorm.DB, model T, opts *Options) HandlerFunc {
+	return aggregateHandler[T](db, model, optionsSource(opts))
+}
+
+// NewAggregateHandlerFromRegistry returns the framework-neutral form of AggregateHandlerFromRegistry.
+func NewAggregateHandlerFromRegistry[T any](db *gorm.DB, model T, reg *Registry, name string) HandlerFunc {
+	return aggregateHandler[T](db, model, reg.source(name))
+}
+
+// resolveSnapshot loads the current snapshot and resolves it for the request,
+// writing the error response on failure. A source that cannot produce a snapshot
+// (unknown registry name, invalid options) is a server misconfiguration and yields 500.
+func resolveSnapshot(rc RequestContext, source snapshotSource) (*Snapshot, bool) {
+	s, err := source()
+	if err != nil {
+		rc.JSON(http.StatusInternalServerError, errorBody(err))
+		return nil, false
+	}
+	if s, err = s.Resolve(rc.Context()); err != nil {
+		rc.JSON(validationStatus(err), errorBody(err))
+		return nil, false
+	}
+	tz := rc.Query().Get(TimezoneParam)
+	if tz == "" {
+		tz = rc.Header(TimezoneHeader)
+	}
+	if s, err = s.withTimezone(tz); err != nil {
+		rc.JSON(http.StatusBadRequest, errorBody(err))
+		return nil, false
+	}
+	return s, true
+}
+
+func searchHandler[T any](db *gorm.DB, model T, source snapshotSource) HandlerFunc {
+	return func(rc RequestContext) {
+		s, ok := resolveSnapshot(rc, source)
+		if !ok {
+			return
+		}
+
+		explain, plan, status, err := explainMode(rc, s.opts)
+		if err != nil {
+			rc.JSON(status, errorBody(err))
+			return
+		}
+
+		query, err := s.ParseQuery(rc.Query())
+		if err != nil {
+			rc.JSON(http.StatusBadRequest, errorBody(err))
+			return
+		}
+
+		ctx, cancel := queryContext(rc.Context(), s.opts.StatementTimeout)
+		defer cancel()
+
+		if explain {
+			serveExplain(rc, ctx, db, s.opts, plan, func(tx *gorm.DB) *gorm.DB {
+				return s.Apply(tx.Model(&model), query)
+			})
+			return
+		}
+
+		var results interface{}
+		err = runQuery(ctx, db, s.opts.StatementTimeout, func(tx *gorm.DB) error {
+			var err error
+			results, err = findResults[T](s.Apply(tx.Model(&model), query), len(query.Fields) > 0)
+			return err
+		})
+		if err != nil {
+			rc.JSON(queryStatus(err), errorBody(err))
+			return
+		}
+		if results, err = s.Redact(db, &model, results); err != nil {
+			rc.JSON(http.StatusInternalServerError, errorBody(err))
+			return
+		}
+		rc.JSON(http.StatusOK, results)
+	}
+}
+
+// findResults runs tx into []T, or into column maps when a projection is applied.
+func findResults[T any](tx *gorm.DB, projected bool) (interface{}, error) {
+	if projected {
+		var rows []map[string]interface{}
+		err := tx.Find(&rows).Error
+		return rows, err
+	}
+	var results []T
+	err := tx.Find(&results).Error
+	return results, err
+}
+
+// AdvancedSearchRequest is the JSON payload for AdvancedSearchHandlerWithOptions.
+type AdvancedSearchRequest struct {
+	Filters    *FilterGroup `json:"filters"`
+	Sort       []SortOption `json:"sort"`
+	Pagination Pagination   `json:"pagination"`
+	Query      string       `json:"query"`
+
+	// Facets requests per-field value counts; when set, the response becomes
+	// {"results": [...], "facets": {"field": [{"value": ..., "count": ...}]}}.
+	Facets []FacetRequest `json:"facets"`
+
+	// Select projects results to the given fields (Options.SelectableFields);
+	// projected results are returned as maps keyed by column.
+	Select []string `json:"select"`
+
+	// Include preloads allowlisted associations (Options.Includes). It cannot be combined with Select.
+	Include []string `json:"include"`
+}
+
+// prepare validates and normalizes req against s in place, returning the validated projection and
+// includes. On error, status is the HTTP status to report.
+func (req *AdvancedSearchRequest) prepare(s *Snapshot) (fields, includes []string, status int, err error) {
+	v, caster, opts := s.validator, s.caster, s.opts
+
+	if err := v.ValidateFilterGroup(req.Filters); err != nil {
+		if opts.StrictJSON {
+			return nil, nil, validationStatus(err), err
+		}
+		req.Filters = nil
+	}
+
+	if err := NormalizeFilterGroupValues(req.Filters, caster); err != nil {
+		if opts.StrictJSON {
+			return nil, nil, http.StatusBadRequest, err
+		}
+		req.Filters = nil
+	}
+
+	var near []Filter
+	if req.Filters != nil {
+		for _, leaf := range req.Filters.And {
+			if leaf.Filter != nil {
+				near = append(near, *leaf.Filter)
+			}
+		}
+	}
+	for i := range req.Sort {
+		norm, err := v.ValidateSortOption(v.withSortCenter(req.Sort[i], near))
+		if err != nil {
+			if opts.StrictJSON {
+				return nil, nil, validationStatus(err), err
+			}
+			req.Sort[i] = SortOption{}
+			continue
+		}
+		req.Sort[i] = norm
+	}
+
+	if err := s.validateFacets(req.Facets); err != nil {
+		return nil, nil, validationStatus(err), err
+	}
+
+	fields, err = s.validateSelect(req.Select)
+	if err != nil {
+		if opts.StrictJSON {
+			return nil, nil, validationStatus(err), err
+		}
+		fields = nil
+	}
+
+	includes, err = s.validateIncludes(req.Include)
+	if err == nil && len(fields) > 0 && len(includes) > 0 {
+		err = errors.New("select and include cannot be combined")
+	}
+	if err != nil {
+		if opts.StrictJSON {
+			return nil, nil, validationStatus(err), err
+		}
+		includes = nil
+	}
+	return fields, includes, 0, nil
+}
+
+// apply builds the query for a prepared request on tx (already bound to the model).
+func (req *AdvancedSearchRequest) apply(tx *gorm.DB, s *Snapshot, fields, includes []string) *gorm.DB {
+	opts := s.opts
+	tx = s.ApplyScopes(tx)
+
+	if req.Filters != nil {
+		tx = req.Filters.Apply(tx)
+	}
+
+	tx = applyQuickSearch(tx, req.Query, s)
+	tx = ApplySelect(tx, fields)
+	tx = ApplyIncludes(tx, includes, opts)
+
+	for _, sort := range req.Sort {
+		if sort.Field == "" {
+			continue
+		}
+		tx = s.validator.orderBy(tx, sort)
+	}
+
+	limit := req.Pagination.Limit
+	if opts.MaxLimit > 0 && limit > opts.MaxLimit {
+		limit = opts.MaxLimit
+	}
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	if req.Pagination.Offset > 0 {
+		tx = tx.Offset(req.Pagination.Offset)
+	}
+	return tx
+}
+
+func advancedSearchHandler[T any](db *gorm.DB, model T, source snapshotSource) HandlerFunc {
+	return func(rc RequestContext) {
+		var req AdvancedSearchRequest
+		if err := rc.BindJSON(&req); err != nil {
+			rc.JSON(http.StatusBadRequest, errorBody(err))
+			return
+		}
+
+		s, ok := resolveSnapshot(rc, source)
+		if !ok {
+			return
+		}
+		opts := s.opts
+
+		explain, plan, status, err := explainMode(rc, opts)
+		if err != nil {
+			rc.JSON(status, errorBody(err))
+			return
+		}
+
+		fields, includes, status, err := req.prepare(s)
+		if err != nil {
+			rc.JSON(status, errorBody(err))
+			return
+		}
+		build := func(tx *gorm.DB) *gorm.DB {
+			return req.apply(tx.Model(&model), s, fields, includes)
+		}
+
+		ctx, cancel := queryContext(rc.Context(), opts.StatementTimeout)
+		defer cancel()
+
+		if explain {
+			serveExplain(rc, ctx, db, opts, plan, build)
+			return
+		}
+
+		// Facet queries run concurrently with the main query (bounded by the same context). Inside the
+		// Postgres statement timeout transaction they share its connection and run after it instead.
+		var (
+			results interface{}
+			facets  map[string][]FacetValue
+		)
+		err = runQuery(ctx, db, opts.StatementTimeout, func(tx *gorm.DB) error {
+			newDB := func() *gorm.DB { return s.ApplyScopes(tx.Model(&model)) }
+			if len(req.Facets) == 0 || inTransaction(tx) {
+				var err error
+				if results, err = findResults[T](build(tx), len(fields) > 0); err != nil {
+					return err
+				}
+				if len(req.Facets) > 0 {
+					facets, err = runFacets(newDB, req.Filters, req.Query, req.Facets, s)
+				}
+				return err
+			}
+
+			var (
+				wg       sync.WaitGroup
+				facetErr error
+			)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				facets, facetErr = runFacets(newDB, req.Filters, req.Query, req.Facets, s)
+			}()
+
+			var err error
+			results, err = findResults[T](build(tx), len(fields) > 0)
+			wg.Wait()
+			if err == nil {
+				err = facetErr
+			}
+			return err
+		})
+		if err != nil {
+			rc.JSON(queryStatus(err), errorBody(err))
+			return
+		}
+		if results, err = s.Redact(db, &model, results); err != nil {
+			rc.JSON(http.StatusInternalServerError, errorBody(err))
+			return
+		}
+
+		if len(req.Facets) == 0 {
+			rc.JSON(http.StatusOK, results)
+			return
+		}
+		rc.JSON(http.StatusOK, map[string]interface{}{"results": results, "facets": facets})
+	}
+}
+
+func aggregateHandler[T any](db *gorm.DB, model T, source snapshotSource) HandlerFunc {
+	return func(rc RequestContext) {
+		var req AggregateRequest
+		if err := rc.BindJSON(&req); err != nil {
+			rc.JSON(http.StatusBadRequest, errorBody(err))
+			return
+		}
+
+		s, ok := resolveSnapshot(rc, source)
+		if !ok {
+			return
+		}
+		v, caster, opts := s.validator, s.caster, s.opts
+
+		if err := v.ValidateFilterGroup(req.Filters); err != nil {
+			if opts.StrictJSON {
+				rc.JSON(validationStatus(err), errorBody(err))
+				return
+			}
+			req.Filters = nil
+		}
+
+		if err := NormalizeFilterGroupValues(req.Filters, caster); err != nil {
+			if opts.StrictJSON {
+				rc.JSON(http.StatusBadRequest, errorBody(err))
+				return
+			}
+			req.Filters = nil
+		}
+
+		if err := s.validateAggregate(&req); err != nil {
+			rc.JSON(validationStatus(err), errorBody(err))
+			return
+		}
+		if err := checkDateBuckets(req, dialectName(db)); err != nil {
+			rc.JSON(http.StatusBadRequest, errorBody(err))
+			return
+		}
+
+		explain, plan, status, err := explainMode(rc, opts)
+		if err != nil {
+			rc.JSON(status, errorBody(err))
+			return
+		}
+
+		ctx, cancel := queryContext(rc.Context(), opts.StatementTimeout)
+		defer cancel()
+
+		if explain {
+			serveExplain(rc, ctx, db, opts, plan, func(tx *gorm.DB) *gorm.DB {
+				return ApplyAggregate(s.ApplyScopes(tx.Model(&model)), req, opts)
+			})
+			return
+		}
+
+		var rows []map[string]interface{}
+		err = runQuery(ctx, db, opts.StatementTimeout, func(tx *gorm.DB) error {
+			return ApplyAggregate(s.ApplyScopes(tx.Model(&model)), req, opts).Find(&rows).Error
+		})
+		if err != nil {
+			rc.JSON(queryStatus(err), errorBody(err))
+			return
+		}
+
+		rc.JSON(http.StatusOK, rows)
+	}
+}
@@ -0,0 +1,56 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestHandlerFunc_NetHTTP(t *testing.T) {
+	db := setupScopeTestDB(t)
+	opts := NewOptions([]string{"title"}).WithScope(tenantScope)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /docs", NewSearchHandler[tenantDoc](db, tenantDoc{}, opts))
+	mux.Handle("POST /docs/search", NewAdvancedSearchHandler[tenantDoc](db, tenantDoc{}, opts))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenant, err := strconv.Atoi(r.Header.Get("X-Tenant")); err == nil {
+			r = r.WithContext(context.WithValue(r.Context(), "tenant", tenant)) //nolint:staticcheck
+		}
+		mux.ServeHTTP(w, r)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/docs?filter[title]=a", nil)
+	req.Header.Set("X-Tenant", "2")
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("unexpected response %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	var docs []tenantDoc
+	if err := json.Unmarshal(w.Body.Bytes(), &docs); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(docs) != 1 || docs[0].TenantID != 2 {
+		t.Fatalf("expected one tenant 2 row, got %+v", docs)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/docs/search", bytes.NewBufferString(`{"filters": {"and": [{"filter": {"field": "nope", "op": "eq", "value": 1}}]}}`))
+	req.Header.Set("X-Tenant", "2")
+	handler.ServeHTTP(w, req)
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != http.StatusBadRequest || body["error"] == "" {
+		t.Fatalf("expected 400 with error body, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/docs/search", bytes.NewBufferString(`{}`)))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("missing scope: expected 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,146 @@
+package go_dbsearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Include configures an allowlisted GORM association that clients may preload.
+type Include struct {
+	// Fields restricts the preloaded columns (empty means all columns).
+	// It must contain the keys GORM needs to attach rows (primary and foreign keys).
+	Fields []string
+
+	// Limit, if > 0, caps the number of preloaded rows per parent row, keeping those with the
+	// lowest primary keys. It is supported on has-one and has-many relations and needs window
+	// functions (SQLite 3.25+, MySQL 8+, Postgres).
+	Limit int
+}
+
+// ValidateIncludes validates requested associations against Options.Includes.
+// It trims names and drops duplicates, keeping the requested order.
+func ValidateIncludes(names []string, opts *Options) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if opts == nil {
+		return nil, errors.New("options is required")
+	}
+
+	out := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || !safeFieldRe.MatchString(name) {
+			return nil, fmt.Errorf("include contains invalid characters: %q", name)
+		}
+		if _, ok := opts.Includes[name]; !ok {
+			return nil, fmt.Errorf("include is not allowed: %q", name)
+		}
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	return out, nil
+}
+
+// validateIncludes is ValidateIncludes against s. Includes withheld by Permissions.Includes are
+// rejected with ErrFieldDenied.
+func (s *Snapshot) validateIncludes(names []string) ([]string, error) {
+	if s.validator != nil {
+		for _, name := range names {
+			if _, ok := s.validator.deniedIncludes[strings.TrimSpace(name)]; ok {
+				return nil, fmt.Errorf("%w: include %q", ErrFieldDenied, strings.TrimSpace(name))
+			}
+		}
+	}
+	return ValidateIncludes(names, s.opts)
+}
+
+// ApplyIncludes preloads validated associations (see ValidateIncludes) using their Include settings.
+func ApplyIncludes(db *gorm.DB, names []string, opts *Options) *gorm.DB {
+	if opts == nil {
+		return db
+	}
+	tx := db
+	for _, name := range names {
+		inc, ok := opts.Includes[name]
+		if !ok {
+			continue
+		}
+		var limit clause.Expr
+		if inc.Limit > 0 {
+			expr, err := perParentLimit(tx, name, inc.Limit)
+			if err != nil {
+				_ = tx.AddError(err)
+				return tx
+			}
+			limit = expr
+		}
+		tx = tx.Preload(name, func(db *gorm.DB) *gorm.DB {
+			if len(inc.Fields) > 0 {
+				db = db.Select(inc.Fields)
+			}
+			if inc.Limit > 0 {
+				db = db.Where(limit)
+			}
+			return db
+		})
+	}
+	return tx
+}
+
+// perParentLimit returns the preload condition keeping the first limit rows (by primary key) of
+// relation name for each parent row. GORM adds the parent keys to the preload query itself.
+func perParentLimit(db *gorm.DB, name string, limit int) (clause.Expr, error) {
+	if db.Statement.Model == nil {
+		return clause.Expr{}, fmt.Errorf("include %q: limit requires a model", name)
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(db.Statement.Model); err != nil {
+		return clause.Expr{}, fmt.Errorf("failed to parse gorm model: %w", err)
+	}
+
+	rel, err := limitedRelation(stmt.Schema, name)
+	if err != nil {
+		return clause.Expr{}, err
+	}
+	pk := rel.FieldSchema.PrioritizedPrimaryField
+
+	// The related rows are keyed by the foreign key columns (and the type column of polymorphic relations).
+	partition := make([]string, 0, len(rel.References))
+	for _, ref := range rel.References {
+		partition = append(partition, stmt.Quote(ref.ForeignKey.DBName))
+	}
+	id := stmt.Quote(pk.DBName)
+	sql := fmt.Sprintf("%s IN (SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS include_rank FROM %s) ranked WHERE include_rank <= ?)",
+		id, id, id, strings.Join(partition, ", "), id, stmt.Quote(rel.FieldSchema.Table))
+	return clause.Expr{SQL: sql, Vars: []interface{}{limit}}, nil
+}
+
+// limitedRelation resolves the (possibly nested) relation name on s for an Include with a Limit.
+func limitedRelation(s *schema.Schema, name string) (*schema.Relationship, error) {
+	var rel *schema.Relationship
+	rels := &s.Relationships
+	for _, part := range strings.Split(name, ".") {
+		r, ok := rels.Relations[part]
+		if !ok {
+			return nil, fmt.Errorf("include %q: unknown relation on %s", name, s.Name)
+		}
+		rel, rels = r, &r.FieldSchema.Relationships
+	}
+	if rel.Type != schema.HasMany && rel.Type != schema.HasOne {
+		return nil, fmt.Errorf("include %q: limit is supported on has-one and has-many relations", name)
+	}
+	if rel.FieldSchema.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("include %q: limit requires a primary key on %s", name, rel.FieldSchema.Name)
+	}
+	return rel, nil
+}
@@ -0,0 +1,169 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type includeAuthor struct {
+	ID    uint
+	Name  string
+	Books []includeBook `gorm:"foreignKey:AuthorID"`
+}
+
+type includeBook struct {
+	ID       uint
+	AuthorID uint
+	Title    string
+	Secret   string
+}
+
+type includeReview struct {
+	ID     uint
+	BookID uint
+	Book   includeBook
+}
+
+func setupIncludeTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open DB: %v", err)
+	}
+	if err := db.AutoMigrate(&includeAuthor{}, &includeBook{}); err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	db.Create(&includeAuthor{Name: "Ann", Books: []includeBook{{Title: "A", Secret: "s1"}, {Title: "B", Secret: "s2"}}})
+	return db
+}
+
+func TestValidateIncludes(t *testing.T) {
+	opts := NewOptions([]string{"name"}).WithInclude("Books", Include{})
+
+	if names, err := ValidateIncludes([]string{"Books", " Books"}, opts); err != nil || len(names) != 1 {
+		t.Fatalf("unexpected result: %v %v", names, err)
+	}
+	if _, err := ValidateIncludes([]string{"Secrets"}, opts); err == nil {
+		t.Fatalf("expected error for non-allowlisted include")
+	}
+}
+
+func TestSearchHandler_Include(t *testing.T) {
+	db := setupIncludeTestDB(t)
+	opts := NewOptions([]string{"name"}).
+		WithInclude("Books", Include{Fields: []string{"id", "author_id", "title"}})
+	router := gin.New()
+	router.GET("/authors", SearchHandlerWithOptions[includeAuthor](db, includeAuthor{}, opts))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/authors?include=Books,Unknown", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %d", w.Code)
+	}
+	var authors []includeAuthor
+	if err := json.Unmarshal(w.Body.Bytes(), &authors); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(authors) != 1 || len(authors[0].Books) != 2 {
+		t.Fatalf("expected preloaded books, got %+v", authors)
+	}
+	if authors[0].Books[0].Title == "" || authors[0].Books[0].Secret != "" {
+		t.Fatalf("include field restriction not applied: %+v", authors[0].Books[0])
+	}
+}
+
+func TestAdvancedSearchHandler_IncludeErrors(t *testing.T) {
+	db := setupIncludeTestDB(t)
+	opts := NewOptions([]string{"name"}).
+		WithSelectableFields("name").
+		WithInclude("Books", Include{Limit: 1})
+	router := gin.New()
+	router.POST("/authors", AdvancedSearchHandlerWithOptions[includeAuthor](db, includeAuthor{}, opts))
+
+	for _, body := range []string{`{"include": ["Secrets"]}`, `{"include": ["Books"], "select": ["name"]}`} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/authors", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/authors", bytes.NewBufferString(`{"include": ["Books"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	var authors []includeAuthor
+	if err := json.Unmarshal(w.Body.Bytes(), &authors); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(authors) != 1 || len(authors[0].Books) != 1 {
+		t.Fatalf("expected include limit of 1, got %+v", authors)
+	}
+}
+
+func TestApplyIncludes_LimitPerParent(t *testing.T) {
+	db := setupIncludeTestDB(t)
+	db.Create(&includeAuthor{Name: "Bea", Books: []includeBook{{Title: "C"}, {Title: "D"}, {Title: "E"}}})
+	opts := NewOptions([]string{"name"}).WithInclude("Books", Include{Limit: 2})
+
+	var authors []includeAuthor
+	if err := ApplyIncludes(db.Model(&includeAuthor{}), []string{"Books"}, opts).Order("id").Find(&authors).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(authors) != 2 || len(authors[0].Books) != 2 || len(authors[1].Books) != 2 {
+		t.Fatalf("expected 2 books per author, got %+v", authors)
+	}
+	if authors[1].Books[0].Title != "C" || authors[1].Books[1].Title != "D" {
+		t.Fatalf("expected the first books by id, got %+v", authors[1].Books)
+	}
+}
+
+func TestAdvancedSearchHandler_IncludeDeniedByPolicy(t *testing.T) {
+	db := setupIncludeTestDB(t)
+	opts := NewOptions([]string{"name"}).
+		WithInclude("Books", Include{}).
+		WithPolicy(func(ctx context.Context) (*Permissions, error) {
+			return &Permissions{Includes: map[string]struct{}{}}, nil
+		})
+	router := gin.New()
+	router.POST("/authors", AdvancedSearchHandlerWithOptions[includeAuthor](db, includeAuthor{}, opts))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/authors", bytes.NewBufferString(`{"include": ["Books"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestOptionsConfig_BuildIncludeLimit(t *testing.T) {
+	db := setupIncludeTestDB(t)
+	cfg, err := ParseOptionsJSON([]byte(`{"allowed_fields": ["name"], "includes": {"Books": {"limit": 2}}}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := cfg.Build(db, &includeAuthor{}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	cfg, err = ParseOptionsJSON([]byte(`{"allowed_fields": ["book_id"], "includes": {"Book": {"limit": 1}}}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := cfg.Build(db, &includeReview{}); err == nil || !strings.Contains(err.Error(), "has-one and has-many") {
+		t.Fatalf("expected a limit error for a belongs-to include, got %v", err)
+	}
+}
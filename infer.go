@@ -3,8 +3,11 @@ package go_dbsearch
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // InferFieldTypesFromModel infers FieldTypes (used for casting) from the provided GORM model.
@@ -13,30 +16,56 @@ import (
 // Only fields present in opts.AllowedFields are inferred; others are ignored.
 //
 // Notes:
-//   - This function is best-effort. If a field cannot be resolved, it is not added.
-//   - For timestamps, this looks for time.Time type.
-//   - For dates-only vs timestamps, it defaults to FieldTypeTime (you can override manually).
+//   - This function is best-effort. If a field cannot be resolved, it is not added; use
+//     InferFieldTypesWithReport to list such fields.
+//   - For timestamps, this looks for time.Time (also sql.NullTime and gorm.DeletedAt).
+//   - Timestamps default to FieldTypeTime; columns declared with `gorm:"type:date"` become
+//     FieldTypeCalendarDate.
+//   - sql.Null* wrappers use their value type; other driver.Valuer types use GORM's DataType.
+//   - Types implementing EnumValuer become FieldTypeEnum with their declared opts.Enums values.
+//   - opts.FieldTypes is modified in place; call it before Compile or registering opts, not while serving.
 func InferFieldTypesFromModel(db *gorm.DB, model any, opts *Options) error {
+	_, err := InferFieldTypesWithReport(db, model, opts)
+	return err
+}
+
+// InferReport describes the outcome of InferFieldTypesWithReport.
+type InferReport struct {
+	// Inferred maps allowlisted fields to the type set for them (FieldTypeEnum for EnumValuer types).
+	Inferred map[string]FieldType
+	// Untyped lists allowlisted model fields whose type could not be mapped. They are cast as strings
+	// unless a type is set manually.
+	Untyped []string
+	// Missing lists allowlisted fields that are not columns of the model (e.g. typos or joined columns).
+	Missing []string
+}
+
+// InferFieldTypesWithReport is InferFieldTypesFromModel, also reporting which allowlisted fields were
+// typed and which were not. Lists are sorted.
+func InferFieldTypesWithReport(db *gorm.DB, model any, opts *Options) (*InferReport, error) {
 	if db == nil {
-		return fmt.Errorf("db is nil")
+		return nil, fmt.Errorf("db is nil")
 	}
 	if opts == nil || opts.AllowedFields == nil || len(opts.AllowedFields) == 0 {
-		return fmt.Errorf("opts.AllowedFields is required to infer FieldTypes")
+		return nil, fmt.Errorf("opts.AllowedFields is required to infer FieldTypes")
 	}
 
 	stmt := &gorm.Statement{DB: db}
 	if err := stmt.Parse(model); err != nil {
-		return fmt.Errorf("failed to parse gorm model: %w", err)
+		return nil, fmt.Errorf("failed to parse gorm model: %w", err)
 	}
 	if stmt.Schema == nil {
-		return fmt.Errorf("gorm schema is nil after parse")
+		return nil, fmt.Errorf("gorm schema is nil after parse")
 	}
 
 	if opts.FieldTypes == nil {
 		opts.FieldTypes = map[string]FieldType{}
 	}
 
-	// GORM schema fields contain both Name and DBName.
+	report := &InferReport{Inferred: map[string]FieldType{}}
+	seen := map[string]struct{}{}
+
+	// GORM schema fields contain both Name and DBName (embedded structs are flattened).
 	// We match AllowedFields keys to DBName (recommended) and also allow match to Name.
 	for _, f := range stmt.Schema.Fields {
 		dbName := f.DBName
@@ -49,20 +78,87 @@ func InferFieldTypesFromModel(db *gorm.DB, model any, opts *Options) error {
 			continue
 		}
 
-		ft, ok := inferFieldTypeFromReflect(f.FieldType)
+		// Prefer DBName as the canonical key if it's in allowlist; else use goName.
+		key := goName
+		if okDB {
+			key = dbName
+		}
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		seen[dbName] = struct{}{}
+		seen[goName] = struct{}{}
+
+		if values, ok := inferEnumValues(f.FieldType); ok {
+			opts.WithEnumValues(key, values)
+			report.Inferred[key] = FieldTypeEnum
+			continue
+		}
+
+		ft, ok := inferSchemaFieldType(f)
 		if !ok {
+			report.Untyped = append(report.Untyped, key)
 			continue
 		}
+		opts.FieldTypes[key] = ft
+		report.Inferred[key] = ft
+	}
 
-		// Prefer DBName as the canonical key if it's in allowlist; else use goName.
-		if okDB {
-			opts.FieldTypes[dbName] = ft
-		} else {
-			opts.FieldTypes[goName] = ft
+	for field := range opts.AllowedFields {
+		if _, ok := seen[field]; !ok && lookupColumn(stmt.Schema, field) == nil {
+			report.Missing = append(report.Missing, field)
 		}
 	}
+	sort.Strings(report.Untyped)
+	sort.Strings(report.Missing)
+	return report, nil
+}
 
-	return nil
+// inferSchemaFieldType maps a GORM schema field to a FieldType: from its Go type first, then from
+// GORM's DataType (custom driver.Valuer types). A `type:date` column turns FieldTypeTime into
+// FieldTypeCalendarDate.
+func inferSchemaFieldType(f *schema.Field) (FieldType, bool) {
+	if f == nil {
+		return "", false
+	}
+	ft, ok := inferFieldTypeFromReflect(f.FieldType)
+	if !ok {
+		ft, ok = inferFieldTypeFromDataType(f.DataType)
+	}
+	if ok && ft == FieldTypeTime && isDateColumn(f) {
+		ft = FieldTypeCalendarDate
+	}
+	return ft, ok
+}
+
+func inferFieldTypeFromDataType(dt schema.DataType) (FieldType, bool) {
+	switch dt {
+	case schema.Bool:
+		return FieldTypeBool, true
+	case schema.Int:
+		return FieldTypeInt64, true
+	case schema.Uint:
+		return FieldTypeUint64, true
+	case schema.Float:
+		return FieldTypeFloat64, true
+	case schema.String:
+		return FieldTypeString, true
+	case schema.Time:
+		return FieldTypeTime, true
+	default:
+		return "", false
+	}
+}
+
+// isDateColumn reports whether the column is declared as a SQL DATE (`gorm:"type:date"`).
+func isDateColumn(f *schema.Field) bool {
+	typ := f.TagSettings["TYPE"]
+	if typ == "" {
+		typ = string(f.DataType)
+	}
+	words := strings.Fields(strings.ToLower(typ))
+	return len(words) > 0 && words[0] == "date"
 }
 
 func inferFieldTypeFromReflect(t reflect.Type) (FieldType, bool) {
@@ -83,18 +179,50 @@ func inferFieldTypeFromReflect(t reflect.Type) (FieldType, bool) {
 		return FieldTypeTime, true
 	}
 
+	// Registered types (UUID, decimal, duration, custom) take precedence over kinds.
+	if ft, ok := matchFieldType(t); ok {
+		return ft, true
+	}
+
+	// sql.NullString, sql.Null[T], gorm.DeletedAt and similar wrappers: use the value's type.
+	if v, ok := nullableValueType(t); ok {
+		return inferFieldTypeFromReflect(v)
+	}
+
 	switch t.Kind() {
 	case reflect.String:
 		return FieldTypeString, true
 	case reflect.Bool:
 		return FieldTypeBool, true
-	case reflect.Int:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
 		return FieldTypeInt, true
 	case reflect.Int64:
 		return FieldTypeInt64, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return FieldTypeUint, true
+	case reflect.Uint64:
+		return FieldTypeUint64, true
 	case reflect.Float32, reflect.Float64:
 		return FieldTypeFloat64, true
 	default:
 		return "", false
 	}
 }
+
+// nullableValueType returns the value field type of a struct shaped like sql.NullX: one value
+// field plus a `Valid bool` field.
+func nullableValueType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return nil, false
+	}
+	valid, ok := t.FieldByName("Valid")
+	if !ok || valid.Type.Kind() != reflect.Bool {
+		return nil, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Name != "Valid" {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
@@ -1,6 +1,10 @@
 package go_dbsearch
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -44,3 +48,68 @@ func TestInferFieldTypesFromModel(t *testing.T) {
 		t.Fatalf("CreatedAt expected time, got %v", opts.FieldTypes["CreatedAt"])
 	}
 }
+
+type geoLabel struct{ Lat, Lng float64 }
+
+func (g geoLabel) Value() (driver.Value, error) { return fmt.Sprintf("%g,%g", g.Lat, g.Lng), nil }
+
+func (g *geoLabel) Scan(v interface{}) error { return nil }
+
+type auditInfo struct {
+	By string
+	At time.Time
+}
+
+type richInferModel struct {
+	gorm.Model
+	Nick     sql.NullString
+	Rank     sql.NullInt32
+	Views    sql.Null[int64]
+	Seen     sql.NullTime
+	Ratio    float32
+	Birthday time.Time `gorm:"type:date"`
+	Place    geoLabel
+	Blob     []byte
+	Audit    auditInfo `gorm:"embedded;embeddedPrefix:audit_"`
+}
+
+func TestInferFieldTypesWithReport(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	opts := NewOptions([]string{"id", "created_at", "deleted_at", "nick", "rank", "views", "seen", "ratio",
+		"birthday", "place", "blob", "audit_by", "audit_at", "nope"})
+	report, err := InferFieldTypesWithReport(db, &richInferModel{}, opts)
+	if err != nil {
+		t.Fatalf("infer: %v", err)
+	}
+
+	want := map[string]FieldType{
+		"id":         FieldTypeUint,
+		"created_at": FieldTypeTime,
+		"deleted_at": FieldTypeTime,
+		"nick":       FieldTypeString,
+		"rank":       FieldTypeInt,
+		"views":      FieldTypeInt64,
+		"seen":       FieldTypeTime,
+		"ratio":      FieldTypeFloat64,
+		"birthday":   FieldTypeCalendarDate,
+		"place":      FieldTypeString,
+		"audit_by":   FieldTypeString,
+		"audit_at":   FieldTypeTime,
+	}
+	if !reflect.DeepEqual(report.Inferred, want) {
+		t.Fatalf("inferred:\n got %v\nwant %v", report.Inferred, want)
+	}
+	if !reflect.DeepEqual(opts.FieldTypes, want) {
+		t.Fatalf("opts.FieldTypes: got %v", opts.FieldTypes)
+	}
+	if !reflect.DeepEqual(report.Untyped, []string{"blob"}) {
+		t.Fatalf("untyped: got %v", report.Untyped)
+	}
+	if !reflect.DeepEqual(report.Missing, []string{"nope"}) {
+		t.Fatalf("missing: got %v", report.Missing)
+	}
+}
@@ -6,9 +6,14 @@ import "fmt"
 //
 // Operator-specific behavior:
 //   - IN:       value may be "a,b" or an array; normalized into []interface{}.
-//   - BETWEEN:  value may be "a,b" or an array length 2; normalized into []interface{}{lo, hi}.
+//   - BETWEEN:  value may be "a,b" or an array length 2; normalized into []interface{}{lo, hi}
+//     (a half-open Range for date fields).
 //   - LIKE:     value is converted to string.
+//   - NEAR:     value may be "lat,lng,radius", an array or {"lat", "lng", "radius"}; becomes GeoNear.
+//   - BBOX:     value may be "south,west,north,east", an array or an object; becomes GeoBox.
 //   - Others:   value is normalized to the configured type for the field.
+//
+// Tri-state bool values (null, any) are accepted with eq and in only (see BoolVocabulary).
 func NormalizeFilterGroupValues(g *FilterGroup, caster *ValueCaster) error {
 	if g == nil {
 		return nil
@@ -47,29 +52,39 @@ func normalizeFilterValue(f *Filter, caster *ValueCaster) error {
 
 	switch op {
 	case "LIKE":
-		f.Value = fmt.Sprintf("%v", f.Value)
+		f.Value = caster.normalizeText(f.Field, fmt.Sprintf("%v", f.Value))
 		return nil
 	case "IN":
 		list, err := normalizeJSONList(f.Field, f.Value, caster)
 		if err != nil {
 			return err
 		}
-		f.Value = list
-		return nil
+		f.Value, err = checkTriState(f.Field, op, list)
+		return err
 	case "BETWEEN":
 		pair, err := normalizeJSONBetweenPair(f.Field, f.Value, caster)
 		if err != nil {
 			return err
 		}
-		f.Value = pair
+		if _, err := checkTriState(f.Field, op, pair); err != nil {
+			return err
+		}
+		f.Value = caster.betweenValue(f.Field, pair[0], pair[1])
+		return nil
+	case "NEAR", "BBOX":
+		v, err := caster.normalizeGeo(f.Field, op, f.Value)
+		if err != nil {
+			return err
+		}
+		f.Value = v
 		return nil
 	default:
 		nv, err := caster.NormalizeJSONValue(f.Field, f.Value)
 		if err != nil {
 			return err
 		}
-		f.Value = nv
-		return nil
+		f.Value, err = checkTriState(f.Field, op, nv)
+		return err
 	}
 }
 
@@ -0,0 +1,119 @@
+package go_dbsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// decodeJSON decodes a JSON request body into v, keeping numbers as json.Number so 64-bit
+// integers reach the ValueCaster intact.
+func decodeJSON(body io.Reader, v interface{}) error {
+	if body == nil {
+		return errors.New("invalid request")
+	}
+	dec := json.NewDecoder(body)
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// jsonNumberValue converts a json.Number for an untyped field: int64 or uint64 when integral and in
+// range, float64 otherwise.
+func jsonNumberValue(n json.Number) interface{} {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return u
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
+
+// castInteger parses raw as the integer type t (FieldTypeInt/Int64/Uint/Uint64). Integral values
+// written with a fraction or exponent ("3.0", "1e3") are accepted; fractions and out-of-range
+// values are errors.
+func castInteger(field string, t FieldType, raw string) (interface{}, error) {
+	s := strings.TrimSpace(raw)
+	signed := t == FieldTypeInt || t == FieldTypeInt64
+	bits := 64
+	if t == FieldTypeInt || t == FieldTypeUint {
+		bits = strconv.IntSize
+	}
+
+	i, u, err := parseInteger(s, signed, bits)
+	if errors.Is(err, strconv.ErrSyntax) {
+		// Enough precision that no fraction of a len(s)-digit literal rounds to an integer.
+		f, _, ferr := big.ParseFloat(s, 10, uint(4*len(s)+64), big.ToNearestEven)
+		switch {
+		case ferr != nil || f.IsInf():
+			return nil, fmt.Errorf("invalid %s for %s: %q", t, field, raw)
+		case f.MantExp(nil) > 64:
+			err = strconv.ErrRange
+		case !f.IsInt():
+			return nil, fmt.Errorf("invalid %s for %s: %s is not an integer", t, field, s)
+		default:
+			n, _ := f.Int(nil)
+			i, u, err = parseInteger(n.String(), signed, bits)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("invalid %s for %s: %s overflows %s", t, field, s, t)
+		}
+		return nil, fmt.Errorf("invalid %s for %s: %q", t, field, raw)
+	}
+
+	switch t {
+	case FieldTypeInt:
+		return int(i), nil
+	case FieldTypeInt64:
+		return i, nil
+	case FieldTypeUint:
+		return uint(u), nil
+	default:
+		return u, nil
+	}
+}
+
+func parseInteger(s string, signed bool, bits int) (int64, uint64, error) {
+	if signed {
+		i, err := strconv.ParseInt(s, 10, bits)
+		return i, 0, err
+	}
+	u, err := strconv.ParseUint(s, 10, bits)
+	return 0, u, err
+}
+
+// normalizeInteger converts a decoded JSON value to the integer type t (see castInteger).
+func normalizeInteger(field string, t FieldType, v interface{}) (interface{}, error) {
+	switch vv := v.(type) {
+	case json.Number:
+		return castInteger(field, t, vv.String())
+	case string:
+		return castInteger(field, t, vv)
+	case float64:
+		// Decoders without UseNumber: values beyond 2^53 may already have been rounded.
+		if math.IsInf(vv, 0) || math.IsNaN(vv) {
+			return nil, fmt.Errorf("invalid %s for %s: %v", t, field, vv)
+		}
+		return castInteger(field, t, strconv.FormatFloat(vv, 'f', -1, 64))
+	case int:
+		return castInteger(field, t, strconv.Itoa(vv))
+	case int64:
+		return castInteger(field, t, strconv.FormatInt(vv, 10))
+	case uint:
+		return castInteger(field, t, strconv.FormatUint(uint64(vv), 10))
+	case uint64:
+		return castInteger(field, t, strconv.FormatUint(vv, 10))
+	default:
+		return nil, fmt.Errorf("invalid %s value for %s: %T", t, field, v)
+	}
+}
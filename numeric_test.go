@@ -0,0 +1,170 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCastInteger(t *testing.T) {
+	ok := []struct {
+		t    FieldType
+		raw  string
+		want interface{}
+	}{
+		{FieldTypeInt, "42", 42},
+		{FieldTypeInt, " 3.0 ", 3},
+		{FieldTypeInt64, "1e3", int64(1000)},
+		{FieldTypeInt64, "-9223372036854775808", int64(-9223372036854775808)},
+		{FieldTypeUint, "7", uint(7)},
+		{FieldTypeUint64, "18446744073709551615", uint64(18446744073709551615)},
+	}
+	for _, c := range ok {
+		got, err := castInteger("f", c.t, c.raw)
+		if err != nil || got != c.want {
+			t.Fatalf("%s %q: got %#v, %v; want %#v", c.t, c.raw, got, err, c.want)
+		}
+	}
+
+	bad := []struct {
+		t    FieldType
+		raw  string
+		want string
+	}{
+		{FieldTypeInt, "1.9", "not an integer"},
+		{FieldTypeInt64, "9223372036854775808", "overflows"},
+		{FieldTypeInt64, "1e19", "overflows"},
+		{FieldTypeInt64, "1e999999999", "invalid"},
+		{FieldTypeUint64, "-1", "invalid"},
+		{FieldTypeUint64, "18446744073709551616", "overflows"},
+		{FieldTypeInt, "abc", "invalid"},
+		{FieldTypeInt, "Inf", "invalid"},
+	}
+	for _, c := range bad {
+		_, err := castInteger("f", c.t, c.raw)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Fatalf("%s %q: got %v, want error containing %q", c.t, c.raw, err, c.want)
+		}
+	}
+}
+
+func TestNormalizeJSONValue_Numbers(t *testing.T) {
+	c := NewValueCaster(NewOptions([]string{"id", "n", "name", "x"}).WithFieldTypes(map[string]FieldType{
+		"id":   FieldTypeInt64,
+		"n":    FieldTypeInt,
+		"name": FieldTypeString,
+	}))
+
+	v, err := c.NormalizeJSONValue("id", json.Number("1234567890123456789"))
+	if err != nil || v != int64(1234567890123456789) {
+		t.Fatalf("snowflake: got %#v, %v", v, err)
+	}
+	if _, err := c.NormalizeJSONValue("n", 1.9); err == nil {
+		t.Fatal("expected error for fractional float64")
+	}
+	if _, err := c.NormalizeJSONValue("n", json.Number("1.9")); err == nil {
+		t.Fatal("expected error for fractional json.Number")
+	}
+	if v, _ := c.NormalizeJSONValue("name", json.Number("10")); v != "10" {
+		t.Fatalf("string field: got %#v", v)
+	}
+	for raw, want := range map[string]interface{}{
+		"10":                   int64(10),
+		"18446744073709551615": uint64(18446744073709551615),
+		"2.5":                  2.5,
+	} {
+		if v, _ := c.NormalizeJSONValue("x", json.Number(raw)); v != want {
+			t.Fatalf("untyped %s: got %#v, want %#v", raw, v, want)
+		}
+	}
+}
+
+func TestInferFieldTypes_Unsigned(t *testing.T) {
+	type unsignedModel struct {
+		ID    uint
+		Flake uint64
+		Small int32
+		Tiny  uint8
+	}
+	opts := NewOptions([]string{"id", "flake", "small", "tiny"})
+	if err := InferFieldTypesFromModel(openTagTestDB(t), &unsignedModel{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]FieldType{"id": FieldTypeUint, "flake": FieldTypeUint64, "small": FieldTypeInt, "tiny": FieldTypeUint}
+	if !reflect.DeepEqual(opts.FieldTypes, want) {
+		t.Fatalf("got %v, want %v", opts.FieldTypes, want)
+	}
+}
+
+type snowflakeRow struct {
+	ID   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name string
+}
+
+func TestAdvancedSearch_SnowflakeIDs(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&snowflakeRow{}); err != nil {
+		t.Fatal(err)
+	}
+	// Adjacent IDs above 2^53 collapse to the same float64.
+	db.Create(&[]snowflakeRow{{ID: 1234567890123456789, Name: "a"}, {ID: 1234567890123456790, Name: "b"}})
+
+	opts := NewOptions([]string{"id", "name"}).WithFieldTypes(map[string]FieldType{"id": FieldTypeInt64})
+	body := `{"filters": {"and": [{"filter": {"field": "id", "op": "eq", "value": 1234567890123456789}}]}}`
+
+	router := gin.New()
+	router.POST("/gin", AdvancedSearchHandlerWithOptions[snowflakeRow](db, snowflakeRow{}, opts))
+	for name, serve := range map[string]func(w http.ResponseWriter, r *http.Request){
+		"gin":      router.ServeHTTP,
+		"net/http": NewAdvancedSearchHandler[snowflakeRow](db, snowflakeRow{}, opts).ServeHTTP,
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/gin", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		serve(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", name, w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), `"Name":"a"`) || strings.Contains(w.Body.String(), `"Name":"b"`) {
+			t.Fatalf("%s: expected only row a, got %s", name, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest("POST", "/gin", bytes.NewBufferString(
+			`{"filters": {"and": [{"filter": {"field": "id", "op": "eq", "value": 1.5}}]}}`))
+		serve(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400 for fractional id, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestAggregateHaving_JSONNumbers(t *testing.T) {
+	db := setupAggregateTestDB(t)
+	body := `{"group_by": ["status"], "metrics": [{"func": "count", "alias": "n"}],
+		"having": {"and": [{"filter": {"field": "n", "op": "gt", "value": 1}}]}}`
+	w := httptest.NewRecorder()
+	NewAggregateHandler[aggOrder](db, aggOrder{}, aggregateTestOptions()).
+		ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0]["status"] != "active" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
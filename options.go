@@ -1,5 +1,7 @@
 package go_dbsearch
 
+import "time"
+
 // Options controls how dbsearch parses, validates, casts, and applies search requests.
 //
 // Phase-4 changes:
@@ -10,6 +12,18 @@ type Options struct {
 	// REQUIRED in Phase-4.
 	AllowedFields map[string]struct{}
 
+	// SortableFields, if non-nil, is the allowlist of fields usable in sort.
+	// If nil, AllowedFields is used for sorting too.
+	SortableFields map[string]struct{}
+
+	// FieldOperators optionally restricts the operators accepted per field (canonical or alias form,
+	// e.g. {"status": {"eq", "in"}}). Fields without an entry accept every supported operator.
+	FieldOperators map[string][]string
+
+	// FieldAliases maps external (client-facing) field names to allowlisted columns,
+	// e.g. {"createdAt": "created_at"}. Columns remain addressable by their own name.
+	FieldAliases map[string]string
+
 	// FieldTypes provides optional per-field type information used to cast query-string values
 	// and normalize JSON values.
 	//
@@ -23,26 +37,109 @@ type Options struct {
 
 	// MaxLimit, if > 0, caps pagination limit for both GET and POST handlers.
 	MaxLimit int
+
+	// QuickSearchFields lists the fields searched by the global quick search term
+	// (GET "q" parameter / JSON "query" property). Fields must also be in AllowedFields.
+	QuickSearchFields []string
+
+	// GroupableFields is the allowlist of fields usable in aggregation group_by.
+	GroupableFields map[string]struct{}
+
+	// AggregatableFields is the allowlist of fields usable as aggregation metric inputs
+	// (sum/avg/min/max). count(*) needs no allowlisted field.
+	AggregatableFields map[string]struct{}
+
+	// SelectableFields is the allowlist of fields clients may project with the GET "fields"
+	// parameter / JSON "select" property. Projected results are returned as maps keyed by column.
+	SelectableFields map[string]struct{}
+
+	// Includes is the allowlist of GORM associations (e.g. "Orders", "Orders.Items") clients may
+	// preload with the GET "include" parameter / JSON "include" property.
+	Includes map[string]Include
+
+	// Policy, if set, resolves the caller's Permissions from the request context on every request
+	// (handlers pass the Gin context, so values set with c.Set are visible via ctx.Value).
+	Policy PolicyFunc
+
+	// Scopes return mandatory filters from the request context (e.g. tenant isolation). They are
+	// always ANDed with the client's filters, and clients cannot filter on scoped fields.
+	Scopes []ScopeFunc
+
+	// Redactions hides, masks or hashes result fields (keyed by column) for every caller unless
+	// the Policy returns Permissions.Redactions. Redacted fields cannot be filtered or sorted on.
+	Redactions map[string]Redaction
+
+	// Enums declares the allowed values of FieldTypeEnum fields as external label → stored value,
+	// e.g. {"status": {"active": 1, "inactive": 0}}. Labels match case-insensitively; unknown
+	// labels are rejected (HTTP 400 when StrictJSON is set, in both GET and JSON requests).
+	Enums map[string]map[string]interface{}
+
+	// BoolValues overrides the accepted spellings of FieldTypeBool values, including the tri-state
+	// null and any (see BoolVocabulary). Nil uses DefaultBoolVocabulary.
+	BoolValues *BoolVocabulary
+
+	// TextNormalization normalizes string filter and quick search values per field (Unicode form,
+	// accent and case folding, Arabic/Persian unification, whitespace), optionally matching a
+	// normalized shadow column instead of the field's own (see TextNormalization).
+	TextNormalization map[string]TextNormalization
+
+	// TimeLayouts replaces the accepted input layouts of FieldTypeDate/FieldTypeCalendarDate/FieldTypeTime
+	// fields. Entries are Go time layouts or LayoutEpoch/LayoutEpochSeconds/LayoutEpochMillis, tried in order.
+	// Relative expressions and month/week shorthands are always accepted.
+	TimeLayouts map[string][]string
+
+	// GeoFields declares FieldTypeGeo fields: virtual names (e.g. "location") over a lat/lng column
+	// pair or a point column, filtered with near and bbox and sortable by distance (see GeoField).
+	GeoFields map[string]GeoField
+
+	// MaxGeoRadius, if > 0, caps near radii in meters. Larger radii are rejected like other invalid
+	// values. See applyGeoNear for the accuracy of the SQLite distance.
+	MaxGeoRadius float64
+
+	// Location is the time zone for date values and timestamps without an offset (default UTC).
+	Location *time.Location
+
+	// RequestTimezone lets clients override Location per request with the "tz" query parameter or
+	// the X-Timezone header (IANA names such as "Asia/Tehran"). Unknown zones return HTTP 400.
+	RequestTimezone bool
+
+	// Clock returns the current time for relative date expressions ("now-7d", "today"); nil means
+	// time.Now. Inject a fixed clock in tests.
+	Clock func() time.Time
+
+	// StatementTimeout, if > 0, bounds every query a handler runs. It is applied as a context
+	// deadline and, on postgres, as SET LOCAL statement_timeout. Timeouts return HTTP 504.
+	StatementTimeout time.Duration
+
+	// AllowExplain enables the "explain" query parameter on the handlers (?explain=1 returns the
+	// generated SQL and vars instead of results, ?explain=plan adds the database's EXPLAIN output).
+	// It exposes query internals, including scope values; enable it for trusted callers only.
+	AllowExplain bool
 }
 
 // NewOptions constructs Options with an allowlist.
 // allowedFields must be non-empty for production use.
 func NewOptions(allowedFields []string) *Options {
-	m := make(map[string]struct{}, len(allowedFields))
-	for _, f := range allowedFields {
-		if f == "" {
-			continue
-		}
-		m[f] = struct{}{}
-	}
 	return &Options{
-		AllowedFields: m,
+		AllowedFields: fieldSet(allowedFields),
 		FieldTypes:    map[string]FieldType{},
 		StrictJSON:    true,
 		MaxLimit:      0,
 	}
 }
 
+// fieldSet converts a field list into a set, skipping empty names.
+func fieldSet(fields []string) map[string]struct{} {
+	m := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		if f == "" {
+			continue
+		}
+		m[f] = struct{}{}
+	}
+	return m
+}
+
 // WithFieldTypes sets FieldTypes and returns opts for chaining.
 func (o *Options) WithFieldTypes(types map[string]FieldType) *Options {
 	if o == nil {
@@ -52,6 +149,39 @@ func (o *Options) WithFieldTypes(types map[string]FieldType) *Options {
 	return o
 }
 
+// WithSortableFields sets SortableFields and returns opts for chaining.
+func (o *Options) WithSortableFields(fields ...string) *Options {
+	if o == nil {
+		return o
+	}
+	o.SortableFields = fieldSet(fields)
+	return o
+}
+
+// WithFieldOperators restricts the operators accepted for field and returns opts for chaining.
+func (o *Options) WithFieldOperators(field string, ops ...string) *Options {
+	if o == nil {
+		return o
+	}
+	if o.FieldOperators == nil {
+		o.FieldOperators = map[string][]string{}
+	}
+	o.FieldOperators[field] = ops
+	return o
+}
+
+// WithFieldAlias maps an external field name to a column and returns opts for chaining.
+func (o *Options) WithFieldAlias(alias, column string) *Options {
+	if o == nil {
+		return o
+	}
+	if o.FieldAliases == nil {
+		o.FieldAliases = map[string]string{}
+	}
+	o.FieldAliases[alias] = column
+	return o
+}
+
 // WithMaxLimit sets MaxLimit and returns opts for chaining.
 func (o *Options) WithMaxLimit(max int) *Options {
 	if o == nil {
@@ -61,6 +191,214 @@ func (o *Options) WithMaxLimit(max int) *Options {
 	return o
 }
 
+// WithQuickSearchFields sets QuickSearchFields and returns opts for chaining.
+func (o *Options) WithQuickSearchFields(fields ...string) *Options {
+	if o == nil {
+		return o
+	}
+	o.QuickSearchFields = fields
+	return o
+}
+
+// WithGroupableFields sets GroupableFields and returns opts for chaining.
+func (o *Options) WithGroupableFields(fields ...string) *Options {
+	if o == nil {
+		return o
+	}
+	o.GroupableFields = fieldSet(fields)
+	return o
+}
+
+// WithAggregatableFields sets AggregatableFields and returns opts for chaining.
+func (o *Options) WithAggregatableFields(fields ...string) *Options {
+	if o == nil {
+		return o
+	}
+	o.AggregatableFields = fieldSet(fields)
+	return o
+}
+
+// WithSelectableFields sets SelectableFields and returns opts for chaining.
+func (o *Options) WithSelectableFields(fields ...string) *Options {
+	if o == nil {
+		return o
+	}
+	o.SelectableFields = fieldSet(fields)
+	return o
+}
+
+// WithInclude allowlists a GORM association for preloading and returns opts for chaining.
+func (o *Options) WithInclude(name string, inc Include) *Options {
+	if o == nil {
+		return o
+	}
+	if o.Includes == nil {
+		o.Includes = map[string]Include{}
+	}
+	o.Includes[name] = inc
+	return o
+}
+
+// WithPolicy sets Policy and returns opts for chaining.
+func (o *Options) WithPolicy(policy PolicyFunc) *Options {
+	if o == nil {
+		return o
+	}
+	o.Policy = policy
+	return o
+}
+
+// WithScope appends a mandatory scope and returns opts for chaining.
+func (o *Options) WithScope(scope ScopeFunc) *Options {
+	if o == nil {
+		return o
+	}
+	o.Scopes = append(o.Scopes, scope)
+	return o
+}
+
+// WithRedaction sets the redaction for field and returns opts for chaining.
+func (o *Options) WithRedaction(field string, r Redaction) *Options {
+	if o == nil {
+		return o
+	}
+	if o.Redactions == nil {
+		o.Redactions = map[string]Redaction{}
+	}
+	o.Redactions[field] = r
+	return o
+}
+
+// WithEnum declares field as FieldTypeEnum with labels stored as-is, and returns opts for chaining.
+func (o *Options) WithEnum(field string, labels ...string) *Options {
+	values := make(map[string]interface{}, len(labels))
+	for _, label := range labels {
+		values[label] = label
+	}
+	return o.WithEnumValues(field, values)
+}
+
+// WithEnumValues declares field as FieldTypeEnum with label → stored value mappings,
+// and returns opts for chaining.
+func (o *Options) WithEnumValues(field string, values map[string]interface{}) *Options {
+	if o == nil {
+		return o
+	}
+	if o.Enums == nil {
+		o.Enums = map[string]map[string]interface{}{}
+	}
+	if o.FieldTypes == nil {
+		o.FieldTypes = map[string]FieldType{}
+	}
+	o.Enums[field] = values
+	o.FieldTypes[field] = FieldTypeEnum
+	return o
+}
+
+// WithBoolValues sets BoolValues and returns opts for chaining.
+func (o *Options) WithBoolValues(v BoolVocabulary) *Options {
+	if o == nil {
+		return o
+	}
+	o.BoolValues = &v
+	return o
+}
+
+// WithTextNormalization sets the text normalization for a string field and returns opts for chaining.
+func (o *Options) WithTextNormalization(field string, n TextNormalization) *Options {
+	if o == nil {
+		return o
+	}
+	if o.TextNormalization == nil {
+		o.TextNormalization = map[string]TextNormalization{}
+	}
+	o.TextNormalization[field] = n
+	return o
+}
+
+// WithTimeLayouts sets the accepted input layouts for a date or time field and returns opts for
+// chaining.
+func (o *Options) WithTimeLayouts(field string, layouts ...string) *Options {
+	if o == nil {
+		return o
+	}
+	if o.TimeLayouts == nil {
+		o.TimeLayouts = map[string][]string{}
+	}
+	o.TimeLayouts[field] = layouts
+	return o
+}
+
+// WithGeoField declares field as FieldTypeGeo over the given columns and returns opts for chaining.
+func (o *Options) WithGeoField(field string, g GeoField) *Options {
+	if o == nil {
+		return o
+	}
+	if o.GeoFields == nil {
+		o.GeoFields = map[string]GeoField{}
+	}
+	if o.FieldTypes == nil {
+		o.FieldTypes = map[string]FieldType{}
+	}
+	o.GeoFields[field] = g
+	o.FieldTypes[field] = FieldTypeGeo
+	return o
+}
+
+// WithMaxGeoRadius sets MaxGeoRadius (meters) and returns opts for chaining.
+func (o *Options) WithMaxGeoRadius(meters float64) *Options {
+	if o == nil {
+		return o
+	}
+	o.MaxGeoRadius = meters
+	return o
+}
+
+// WithLocation sets Location and returns opts for chaining.
+func (o *Options) WithLocation(loc *time.Location) *Options {
+	if o == nil {
+		return o
+	}
+	o.Location = loc
+	return o
+}
+
+// WithRequestTimezone sets RequestTimezone and returns opts for chaining.
+func (o *Options) WithRequestTimezone(allow bool) *Options {
+	if o == nil {
+		return o
+	}
+	o.RequestTimezone = allow
+	return o
+}
+
+// WithClock sets Clock and returns opts for chaining.
+func (o *Options) WithClock(clock func() time.Time) *Options {
+	if o == nil {
+		return o
+	}
+	o.Clock = clock
+	return o
+}
+
+// WithStatementTimeout sets StatementTimeout and returns opts for chaining.
+func (o *Options) WithStatementTimeout(timeout time.Duration) *Options {
+	if o == nil {
+		return o
+	}
+	o.StatementTimeout = timeout
+	return o
+}
+
+// WithAllowExplain sets AllowExplain and returns opts for chaining.
+func (o *Options) WithAllowExplain(allow bool) *Options {
+	if o == nil {
+		return o
+	}
+	o.AllowExplain = allow
+	return o
+}
+
 // WithStrictJSON sets StrictJSON and returns opts for chaining.
 func (o *Options) WithStrictJSON(strict bool) *Options {
 	if o == nil {
@@ -74,6 +412,10 @@ func (o *Options) WithStrictJSON(strict bool) *Options {
 type SortOption struct {
 	Field     string `json:"field"`
 	Direction string `json:"direction"`
+
+	// Near is the point a geo field is sorted by distance from. In query strings it is written
+	// "sort=location@lat:lng"; it defaults to the center of a near filter on the same field.
+	Near *GeoPoint `json:"near,omitempty"`
 }
 
 // Pagination represents limit/offset pagination.
@@ -1,6 +1,9 @@
 package go_dbsearch
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -10,13 +13,32 @@ import (
 //
 // Phase-4:
 //   - Options is REQUIRED (to provide AllowedFields).
-//   - Invalid filters/sorts are ignored (GET stays permissive).
+//   - Invalid filters/sorts/fields/includes are ignored (GET stays permissive), except unknown
+//     enum labels, which return ErrInvalidEnumValue when opts.StrictJSON is set.
+//   - include is ignored when fields is set (projected rows cannot carry relations).
 func ParseQueryWithOptions(values url.Values, opts *Options) (SearchQuery, error) {
-	v, err := NewValidatorFromOptions(opts)
+	return ParseQueryWithContext(context.Background(), values, opts)
+}
+
+// ParseQueryWithContext is ParseQueryWithOptions with Options.Policy and Options.Scopes resolved
+// for ctx. Fields and operators withheld by the policy, and filters on scoped fields, are ignored
+// like any other invalid filter. Apply the result with ApplyWithContext so scopes are enforced.
+func ParseQueryWithContext(ctx context.Context, values url.Values, opts *Options) (SearchQuery, error) {
+	s, err := newSnapshot(opts)
 	if err != nil {
 		return SearchQuery{}, err
 	}
-	caster := NewValueCaster(opts)
+	if s, err = s.Resolve(ctx); err != nil {
+		return SearchQuery{}, err
+	}
+	if s, err = s.withTimezone(values.Get(TimezoneParam)); err != nil {
+		return SearchQuery{}, err
+	}
+	return parseQuery(values, s)
+}
+
+func parseQuery(values url.Values, s *Snapshot) (SearchQuery, error) {
+	v, caster, opts := s.validator, s.caster, s.opts
 
 	var filters []Filter
 	var sorts []SortOption
@@ -37,11 +59,8 @@ func ParseQueryWithOptions(values url.Values, opts *Options) (SearchQuery, error
 			op = strings.TrimSpace(parts[1])
 		}
 
-		if err := v.ValidateField(field); err != nil {
-			continue
-		}
-		normOp, ok := NormalizeOperator(op)
-		if !ok {
+		f := Filter{Field: field, Op: op}
+		if err := v.ValidateFilter(&f); err != nil {
 			continue
 		}
 
@@ -50,12 +69,22 @@ func ParseQueryWithOptions(values url.Values, opts *Options) (SearchQuery, error
 			raw = vals[0]
 		}
 
-		value, ok := parseAndCastValue(field, normOp, raw, caster)
-		if !ok {
+		value, err := parseAndCastValue(f.Field, f.Op, raw, caster)
+		if err == nil {
+			value, err = checkTriState(f.Field, f.Op, value)
+		}
+		if err != nil {
+			if errors.Is(err, ErrInvalidEnumValue) && opts != nil && opts.StrictJSON {
+				return SearchQuery{}, err
+			}
+			continue
+		}
+		if _, ok := value.(matchAny); ok {
 			continue
 		}
+		f.Value = value
 
-		filters = append(filters, Filter{Field: field, Op: normOp, Value: value})
+		filters = append(filters, f)
 	}
 
 	sortStr := strings.TrimSpace(values.Get("sort"))
@@ -71,10 +100,36 @@ func ParseQueryWithOptions(values url.Values, opts *Options) (SearchQuery, error
 				dir = "DESC"
 				field = strings.TrimPrefix(part, "-")
 			}
-			if err := v.ValidateField(field); err != nil {
+			field, near, err := parseSortPoint(field)
+			if err != nil {
+				continue
+			}
+			opt := v.withSortCenter(SortOption{Field: field, Direction: dir, Near: near}, filters)
+			norm, err := v.ValidateSortOption(opt)
+			if err != nil {
 				continue
 			}
-			sorts = append(sorts, SortOption{Field: field, Direction: dir})
+			sorts = append(sorts, norm)
+		}
+	}
+
+	var fields []string
+	if opts != nil && len(opts.SelectableFields) > 0 {
+		sv := NewValidator(opts.SelectableFields)
+		for _, f := range splitCSV(values.Get("fields")) {
+			if err := sv.ValidateField(f); err != nil {
+				continue
+			}
+			fields = append(fields, f)
+		}
+	}
+
+	var includes []string
+	if opts != nil && len(fields) == 0 {
+		for _, name := range splitCSV(values.Get("include")) {
+			if valid, err := ValidateIncludes([]string{name}, opts); err == nil {
+				includes = append(includes, valid...)
+			}
 		}
 	}
 
@@ -92,10 +147,13 @@ func ParseQueryWithOptions(values url.Values, opts *Options) (SearchQuery, error
 			Limit:  limit,
 			Offset: offset,
 		},
+		Query:    strings.TrimSpace(values.Get("q")),
+		Fields:   fields,
+		Includes: includes,
 	}, nil
 }
 
-func parseAndCastValue(field, op, raw string, caster *ValueCaster) (interface{}, bool) {
+func parseAndCastValue(field, op, raw string, caster *ValueCaster) (interface{}, error) {
 	switch op {
 	case "IN":
 		parts := splitCSV(raw)
@@ -103,30 +161,28 @@ func parseAndCastValue(field, op, raw string, caster *ValueCaster) (interface{},
 		for _, p := range parts {
 			cv, err := caster.CastFromString(field, p)
 			if err != nil {
-				return nil, false
+				return nil, err
 			}
 			out = append(out, cv)
 		}
-		return out, true
+		return out, nil
 	case "BETWEEN":
 		parts := splitCSV(raw)
 		if len(parts) != 2 {
-			return nil, false
+			return nil, fmt.Errorf("BETWEEN value must have 2 items for %s", field)
 		}
 		lo, err := caster.CastFromString(field, parts[0])
 		if err != nil {
-			return nil, false
+			return nil, err
 		}
 		hi, err := caster.CastFromString(field, parts[1])
 		if err != nil {
-			return nil, false
+			return nil, err
 		}
-		return []interface{}{lo, hi}, true
+		return caster.betweenValue(field, lo, hi), nil
+	case "NEAR", "BBOX":
+		return caster.castGeo(field, op, raw)
 	default:
-		cv, err := caster.CastFromString(field, raw)
-		if err != nil {
-			return nil, false
-		}
-		return cv, true
+		return caster.CastFromString(field, raw)
 	}
 }
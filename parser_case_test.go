@@ -3,6 +3,7 @@ package go_dbsearch
 import (
 	"net/url"
 	"testing"
+	"time"
 )
 
 func TestParseQueryWithOptions_IN_BETWEEN_Casting(t *testing.T) {
@@ -45,14 +46,18 @@ func TestParseQueryWithOptions_IN_BETWEEN_Casting(t *testing.T) {
 		t.Fatalf("age IN filter not found")
 	}
 
-	// created_at BETWEEN -> []interface{}{time, time}
+	// created_at BETWEEN on a date -> Range covering whole days, end exclusive
 	var foundBetween bool
 	for _, f := range q.Filters {
 		if f.Field == "created_at" && f.Op == "BETWEEN" {
 			foundBetween = true
-			pair, ok := f.Value.([]interface{})
-			if !ok || len(pair) != 2 {
-				t.Fatalf("between expected []interface{} len=2, got %T %#v", f.Value, f.Value)
+			r, ok := f.Value.(Range)
+			if !ok {
+				t.Fatalf("between expected Range, got %T %#v", f.Value, f.Value)
+			}
+			to, _ := r.To.(time.Time)
+			if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !to.Equal(want) {
+				t.Fatalf("between end: got %v, want %v", r.To, want)
 			}
 		}
 	}
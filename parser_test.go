@@ -5,10 +5,19 @@ import (
 	"testing"
 )
 
+func parseTestQuery(t *testing.T, v url.Values) SearchQuery {
+	t.Helper()
+	q, err := ParseQueryWithOptions(v, NewOptions([]string{"name", "age"}))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return q
+}
+
 func TestParseQuery_SingleFilter(t *testing.T) {
 	v := url.Values{}
 	v.Set("filter[name:=]", "Alice")
-	q := ParseQuery(v)
+	q := parseTestQuery(t, v)
 	if len(q.Filters) != 1 || q.Filters[0].Field != "name" || q.Filters[0].Op != "=" || q.Filters[0].Value != "Alice" {
 		t.Fatalf("Single filter parse failed: %+v", q.Filters)
 	}
@@ -17,8 +26,8 @@ func TestParseQuery_SingleFilter(t *testing.T) {
 func TestParseQuery_MultipleFilters(t *testing.T) {
 	v := url.Values{}
 	v.Set("filter[name:=]", "Alice")
-	v.Set("filter[age:>]=", "25")
-	q := ParseQuery(v)
+	v.Set("filter[age:>]", "25")
+	q := parseTestQuery(t, v)
 	if len(q.Filters) != 2 {
 		t.Fatalf("Multiple filters parse failed: %+v", q.Filters)
 	}
@@ -27,7 +36,7 @@ func TestParseQuery_MultipleFilters(t *testing.T) {
 func TestParseQuery_SortAscDesc(t *testing.T) {
 	v := url.Values{}
 	v.Set("sort", "-age,name")
-	q := ParseQuery(v)
+	q := parseTestQuery(t, v)
 	if len(q.Sorts) != 2 || q.Sorts[0].Field != "age" || q.Sorts[0].Direction != "DESC" || q.Sorts[1].Field != "name" || q.Sorts[1].Direction != "ASC" {
 		t.Fatalf("Sort parse failed: %+v", q.Sorts)
 	}
@@ -37,7 +46,7 @@ func TestParseQuery_Pagination(t *testing.T) {
 	v := url.Values{}
 	v.Set("limit", "10")
 	v.Set("offset", "5")
-	q := ParseQuery(v)
+	q := parseTestQuery(t, v)
 	if q.Pagination.Limit != 10 || q.Pagination.Offset != 5 {
 		t.Fatalf("Pagination parse failed: %+v", q.Pagination)
 	}
@@ -46,7 +55,7 @@ func TestParseQuery_Pagination(t *testing.T) {
 func TestParseQuery_InvalidInput(t *testing.T) {
 	v := url.Values{}
 	v.Set("filter[bad]", "x")
-	q := ParseQuery(v)
+	q := parseTestQuery(t, v)
 	if len(q.Filters) != 0 {
 		t.Fatalf("Invalid filter should be ignored: %+v", q.Filters)
 	}
@@ -0,0 +1,194 @@
+package go_dbsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrPolicy wraps errors returned by Options.Policy.
+var ErrPolicy = errors.New("search policy rejected the request")
+
+// Permissions narrows Options for a single request. Zero values keep the Options behavior.
+type Permissions struct {
+	// AllowedFields, if non-nil, is the set of fields the caller may use. It is intersected with every
+	// Options allowlist (filter, sort, select, group, aggregate, quick search). Allowlisted fields
+	// outside it are rejected with ErrFieldDenied instead of ErrFieldNotAllowed.
+	AllowedFields map[string]struct{}
+
+	// Operators, if non-nil, is the set of operators the caller may use (canonical or alias form).
+	Operators []string
+
+	// MaxLimit, if > 0, caps pagination below Options.MaxLimit.
+	MaxLimit int
+
+	// Includes, if non-nil, is the set of Options.Includes the caller may preload. Other allowlisted
+	// includes are rejected with ErrFieldDenied.
+	Includes map[string]struct{}
+
+	// Redactions, if non-nil, replaces Options.Redactions for the caller
+	// (an empty map lifts every redaction).
+	Redactions map[string]Redaction
+}
+
+// PolicyFunc resolves the caller's Permissions from the request context.
+//
+// Returning nil Permissions grants everything Options allows; returning an error rejects the
+// request (HTTP 403 in the handlers). ParseQueryWithOptions calls it with context.Background().
+type PolicyFunc func(ctx context.Context) (*Permissions, error)
+
+// Resolve applies Options.Policy, Options.Redactions and Options.Scopes for ctx and returns the
+// effective snapshot. Without any of them it returns s unchanged.
+func (s *Snapshot) Resolve(ctx context.Context) (*Snapshot, error) {
+	if s.opts == nil {
+		return s, nil
+	}
+	var perms *Permissions
+	if s.opts.Policy != nil {
+		p, err := s.opts.Policy(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrPolicy, err)
+		}
+		perms = p
+	}
+	if perms == nil {
+		if s.redacted != nil {
+			return s.redacted.withScopes(ctx)
+		}
+		return s.withScopes(ctx)
+	}
+	r, err := s.restrict(perms)
+	if err != nil {
+		return nil, err
+	}
+	return r.withScopes(ctx)
+}
+
+// restrict returns a snapshot limited to perms.
+func (s *Snapshot) restrict(perms *Permissions) (*Snapshot, error) {
+	opts := s.opts.Clone()
+
+	var denied map[string]struct{}
+	if perms.AllowedFields != nil {
+		denied = map[string]struct{}{}
+		for _, set := range []map[string]struct{}{opts.AllowedFields, opts.SortableFields, opts.SelectableFields,
+			opts.GroupableFields, opts.AggregatableFields} {
+			for f := range set {
+				if _, ok := perms.AllowedFields[f]; !ok {
+					denied[f] = struct{}{}
+				}
+			}
+		}
+
+		opts.AllowedFields = intersectSet(opts.AllowedFields, perms.AllowedFields)
+		opts.SortableFields = intersectSet(opts.SortableFields, perms.AllowedFields)
+		opts.SelectableFields = intersectSet(opts.SelectableFields, perms.AllowedFields)
+		opts.GroupableFields = intersectSet(opts.GroupableFields, perms.AllowedFields)
+		opts.AggregatableFields = intersectSet(opts.AggregatableFields, perms.AllowedFields)
+
+		quick := opts.QuickSearchFields[:0]
+		for _, f := range opts.QuickSearchFields {
+			if _, ok := perms.AllowedFields[f]; ok {
+				quick = append(quick, f)
+			}
+		}
+		opts.QuickSearchFields = quick
+	}
+	if perms.Redactions != nil {
+		opts.Redactions = perms.Redactions
+	}
+	for f, r := range opts.Redactions {
+		if err := r.validate(f); err != nil {
+			return nil, err
+		}
+		if denied == nil {
+			denied = map[string]struct{}{}
+		}
+		denied[f] = struct{}{}
+		delete(opts.AllowedFields, f)
+		delete(opts.SortableFields, f)
+		delete(opts.GroupableFields, f)
+		delete(opts.AggregatableFields, f)
+		opts.QuickSearchFields = removeField(opts.QuickSearchFields, f)
+	}
+	if perms.MaxLimit > 0 && (opts.MaxLimit == 0 || perms.MaxLimit < opts.MaxLimit) {
+		opts.MaxLimit = perms.MaxLimit
+	}
+	var deniedIncludes map[string]struct{}
+	if perms.Includes != nil {
+		for name := range opts.Includes {
+			if _, ok := perms.Includes[name]; !ok {
+				if deniedIncludes == nil {
+					deniedIncludes = map[string]struct{}{}
+				}
+				deniedIncludes[name] = struct{}{}
+				delete(opts.Includes, name)
+			}
+		}
+	}
+
+	v, err := newValidatorFromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	v.denied = denied
+	v.deniedIncludes = deniedIncludes
+	if perms.Operators != nil {
+		v.permittedOps = make(map[string]struct{}, len(perms.Operators))
+		for _, op := range perms.Operators {
+			n, err := ValidateOperator(op)
+			if err != nil {
+				return nil, fmt.Errorf("Permissions.Operators: %w", err)
+			}
+			v.permittedOps[n] = struct{}{}
+		}
+	}
+
+	return &Snapshot{opts: opts, validator: v, caster: NewValueCaster(opts)}, nil
+}
+
+// deniedField returns checkField's error for field if it was withheld by Permissions, nil
+// otherwise; the exported *Options validators report everything else.
+func (s *Snapshot) deniedField(allowed map[string]struct{}, field string) error {
+	if s.validator == nil {
+		return nil
+	}
+	if err := s.validator.checkField(allowed, field); errors.Is(err, ErrFieldDenied) {
+		return err
+	}
+	return nil
+}
+
+// validationStatus maps a validation error to an HTTP status:
+// 403 for policy and scope denials, 400 otherwise.
+func validationStatus(err error) int {
+	if errors.Is(err, ErrFieldDenied) || errors.Is(err, ErrOperatorDenied) || errors.Is(err, ErrPolicy) ||
+		errors.Is(err, ErrScope) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+func removeField(fields []string, field string) []string {
+	out := fields[:0]
+	for _, f := range fields {
+		if f != field {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func intersectSet(set, keep map[string]struct{}) map[string]struct{} {
+	if set == nil {
+		return nil
+	}
+	out := make(map[string]struct{}, len(set))
+	for f := range set {
+		if _, ok := keep[f]; ok {
+			out[f] = struct{}{}
+		}
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func rolePolicy(ctx context.Context) (*Permissions, error) {
+	switch ctx.Value("role") {
+	case "admin":
+		return nil, nil
+	case "user":
+		return &Permissions{
+			AllowedFields: map[string]struct{}{"name": {}, "email": {}},
+			Operators:     []string{"eq", "like"},
+			MaxLimit:      1,
+		}, nil
+	default:
+		return nil, errors.New("unauthenticated")
+	}
+}
+
+func policyTestOptions() *Options {
+	return NewOptions([]string{"name", "email", "age"}).WithPolicy(rolePolicy)
+}
+
+func TestSnapshotResolve_DeniedVsUnknown(t *testing.T) {
+	s, err := Compile(policyTestOptions())
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	user, err := s.Resolve(context.WithValue(context.Background(), "role", "user")) //nolint:staticcheck
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if err := user.Validator().ValidateFilter(&Filter{Field: "age", Op: "eq"}); !errors.Is(err, ErrFieldDenied) {
+		t.Fatalf("expected ErrFieldDenied, got %v", err)
+	}
+	if err := user.Validator().ValidateFilter(&Filter{Field: "salary", Op: "eq"}); !errors.Is(err, ErrFieldNotAllowed) {
+		t.Fatalf("expected ErrFieldNotAllowed, got %v", err)
+	}
+	if err := user.Validator().ValidateFilter(&Filter{Field: "name", Op: "in"}); !errors.Is(err, ErrOperatorDenied) {
+		t.Fatalf("expected ErrOperatorDenied, got %v", err)
+	}
+	if user.Options().MaxLimit != 1 {
+		t.Fatalf("expected MaxLimit 1, got %d", user.Options().MaxLimit)
+	}
+
+	if _, err := s.Resolve(context.Background()); !errors.Is(err, ErrPolicy) {
+		t.Fatalf("expected ErrPolicy, got %v", err)
+	}
+}
+
+func TestParseQueryWithContext_Policy(t *testing.T) {
+	values := url.Values{}
+	values.Set("filter[name:eq]", "Alice")
+	values.Set("filter[age:gt]", "20")
+
+	ctx := context.WithValue(context.Background(), "role", "user") //nolint:staticcheck
+	q, err := ParseQueryWithContext(ctx, values, policyTestOptions())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(q.Filters) != 1 || q.Filters[0].Field != "name" {
+		t.Fatalf("denied filter should be ignored in GET mode: %+v", q.Filters)
+	}
+}
+
+func TestAdvancedSearchHandler_PolicyFromGinContext(t *testing.T) {
+	db := setupTestDB(t)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("role", c.GetHeader("X-Role"))
+	})
+	router.POST("/test", AdvancedSearchHandlerWithOptions[TestModel](db, TestModel{}, policyTestOptions()))
+
+	post := func(role, body string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/test", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Role", role)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	ageFilter := `{"filters": {"and": [{"filter": {"field": "age", "op": "gt", "value": 1}}]}}`
+	unknownFilter := `{"filters": {"and": [{"filter": {"field": "salary", "op": "eq", "value": 1}}]}}`
+
+	if code := post("admin", ageFilter); code != http.StatusOK {
+		t.Fatalf("admin: expected 200, got %d", code)
+	}
+	if code := post("user", ageFilter); code != http.StatusForbidden {
+		t.Fatalf("user denied field: expected 403, got %d", code)
+	}
+	if code := post("user", unknownFilter); code != http.StatusBadRequest {
+		t.Fatalf("user unknown field: expected 400, got %d", code)
+	}
+	if code := post("", `{}`); code != http.StatusForbidden {
+		t.Fatalf("anonymous: expected 403, got %d", code)
+	}
+}
+
+func TestHandlers_PolicyDeniedOutputFields(t *testing.T) {
+	db := setupTestDB(t)
+	// Facets run alongside the main query; keep them on the one in-memory database.
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	opts := policyTestOptions().
+		WithSelectableFields("name", "age").
+		WithGroupableFields("name", "age").
+		WithAggregatableFields("age").
+		WithStrictJSON(true)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("role", "user")
+	})
+	router.POST("/search", AdvancedSearchHandlerWithOptions[TestModel](db, TestModel{}, opts))
+	router.POST("/aggregate", AggregateHandlerWithOptions[TestModel](db, TestModel{}, opts))
+
+	cases := []struct {
+		path, body string
+		want       int
+	}{
+		{"/search", `{"select": ["age"]}`, http.StatusForbidden},
+		{"/search", `{"select": ["salary"]}`, http.StatusBadRequest},
+		{"/search", `{"facets": [{"field": "age"}]}`, http.StatusForbidden},
+		{"/search", `{"facets": [{"field": "salary"}]}`, http.StatusBadRequest},
+		{"/search", `{"select": ["name"], "facets": [{"field": "name"}]}`, http.StatusOK},
+		{"/aggregate", `{"group_by": ["age"]}`, http.StatusForbidden},
+		{"/aggregate", `{"group_by": ["salary"]}`, http.StatusBadRequest},
+		{"/aggregate", `{"metrics": [{"func": "sum", "field": "age"}]}`, http.StatusForbidden},
+		{"/aggregate", `{"group_by": ["name"]}`, http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", tc.path, bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d: %s", tc.path, tc.body, tc.want, w.Code, w.Body.String())
+		}
+	}
+}
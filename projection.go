@@ -0,0 +1,56 @@
+package go_dbsearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ValidateSelect validates projected fields against Options.SelectableFields.
+// It trims names and drops duplicates, keeping the requested order.
+func ValidateSelect(fields []string, opts *Options) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if opts == nil {
+		return nil, errors.New("options is required")
+	}
+
+	v := NewValidator(opts.SelectableFields)
+	out := make([]string, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if err := v.ValidateField(f); err != nil {
+			return nil, fmt.Errorf("select: %w", err)
+		}
+		if _, dup := seen[f]; dup {
+			continue
+		}
+		seen[f] = struct{}{}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// validateSelect is ValidateSelect against s. Fields withheld by Permissions are rejected with
+// ErrFieldDenied.
+func (s *Snapshot) validateSelect(fields []string) ([]string, error) {
+	for _, f := range fields {
+		if err := s.deniedField(s.opts.SelectableFields, f); err != nil {
+			return nil, fmt.Errorf("select: %w", err)
+		}
+	}
+	return ValidateSelect(fields, s.opts)
+}
+
+// ApplySelect restricts the query to validated fields (see ValidateSelect).
+// An empty list leaves the query unchanged.
+func ApplySelect(db *gorm.DB, fields []string) *gorm.DB {
+	if len(fields) == 0 {
+		return db
+	}
+	return db.Select(fields)
+}
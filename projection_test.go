@@ -0,0 +1,80 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidateSelect(t *testing.T) {
+	opts := NewOptions([]string{"name"}).WithSelectableFields("name", "email")
+
+	fields, err := ValidateSelect([]string{" name", "email", "name"}, opts)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(fields) != 2 || fields[0] != "name" || fields[1] != "email" {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+	if _, err := ValidateSelect([]string{"age"}, opts); err == nil {
+		t.Fatalf("expected error for non-selectable field")
+	}
+}
+
+func TestSearchHandler_FieldsProjection(t *testing.T) {
+	db := setupTestDB(t)
+	opts := NewOptions([]string{"name", "age"}).WithSelectableFields("name", "email")
+	router := gin.New()
+	router.GET("/test", SearchHandlerWithOptions[TestModel](db, TestModel{}, opts))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test?fields=name,age&filter[name:eq]=Bob", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %d", w.Code)
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// age is not selectable and is ignored in GET mode.
+	if len(rows) != 1 || len(rows[0]) != 1 || rows[0]["name"] != "Bob" {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+}
+
+func TestAdvancedSearchHandler_SelectStrict(t *testing.T) {
+	db := setupTestDB(t)
+	opts := NewOptions([]string{"name"}).WithSelectableFields("name", "email")
+	router := gin.New()
+	router.POST("/test", AdvancedSearchHandlerWithOptions[TestModel](db, TestModel{}, opts))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/test", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"select": ["email"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(rows) != 2 || len(rows[0]) != 1 || rows[0]["email"] == nil {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+
+	if w := post(`{"select": ["age"]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for non-selectable field, got %d", w.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package go_dbsearch
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ApplyQuickSearch applies a free-text quick search term across Options.QuickSearchFields.
+//
+// The term expands to an OR of per-field matches, ANDed with the rest of the query:
+//   - string (or untyped) fields match with LIKE (contains), after Options.TextNormalization and
+//     against its shadow column when set
+//   - typed fields match with equality, only if the term casts to the field type
+//     (e.g. "42" can match an int field, "abc" cannot)
+//
+// Fields that are not allowlisted are skipped. If no field can match the term, the query
+// matches no rows. A blank term leaves the query unchanged.
+func ApplyQuickSearch(db *gorm.DB, term string, opts *Options) *gorm.DB {
+	if strings.TrimSpace(term) == "" || opts == nil || len(opts.QuickSearchFields) == 0 {
+		return db
+	}
+	s, err := newSnapshot(opts)
+	if err != nil {
+		return db.Where("1 = 0")
+	}
+	return applyQuickSearch(db, term, s)
+}
+
+func applyQuickSearch(db *gorm.DB, term string, s *Snapshot) *gorm.DB {
+	term = strings.TrimSpace(term)
+	if term == "" || s.opts == nil || len(s.opts.QuickSearchFields) == 0 {
+		return db
+	}
+
+	group := buildQuickSearchGroup(term, s)
+	if group == nil {
+		return db.Where("1 = 0")
+	}
+	return group.Apply(db)
+}
+
+// buildQuickSearchGroup returns the OR group for term, or nil if no field can match it.
+func buildQuickSearchGroup(term string, s *Snapshot) *FilterGroup {
+	if s.validator == nil {
+		return nil
+	}
+	caster := s.caster
+
+	group := &FilterGroup{}
+	for _, field := range s.opts.QuickSearchFields {
+		if err := s.validator.ValidateField(field); err != nil {
+			continue
+		}
+
+		t := caster.fieldTypes[field]
+		if t == "" || t == FieldTypeString {
+			f := &Filter{Field: field, Op: "LIKE", Value: caster.normalizeText(field, term)}
+			if column, ok := s.validator.shadow[field]; ok {
+				f.Field = column
+			}
+			group.Or = append(group.Or, FilterGroupOrLeaf{Filter: f})
+			continue
+		}
+
+		value, err := caster.CastFromString(field, term)
+		if err != nil {
+			continue
+		}
+		switch value.(type) {
+		case matchAny, sqlNull:
+			// Tri-state spellings ("any", "null") are filters, not search terms.
+			continue
+		}
+		group.Or = append(group.Or, FilterGroupOrLeaf{Filter: &Filter{Field: field, Op: "=", Value: value}})
+	}
+
+	if len(group.Or) == 0 {
+		return nil
+	}
+	return group
+}
@@ -0,0 +1,88 @@
+package go_dbsearch
+
+import (
+	"net/url"
+	"testing"
+)
+
+func quickSearchTestOptions() *Options {
+	return NewOptions([]string{"name", "email", "age"}).
+		WithFieldTypes(map[string]FieldType{"age": FieldTypeInt}).
+		WithQuickSearchFields("name", "email", "age")
+}
+
+func TestApplyQuickSearch_TypeAware(t *testing.T) {
+	db := setupTestDB(t)
+	opts := quickSearchTestOptions()
+
+	cases := []struct {
+		term string
+		want int
+	}{
+		{"ali", 1},   // name LIKE
+		{"gmail", 1}, // email LIKE
+		{"25", 1},    // age = 25
+		{"zzz", 0},
+		{"", 2},
+	}
+	for _, tc := range cases {
+		var result []TestModel
+		if err := ApplyQuickSearch(db.Model(&TestModel{}), tc.term, opts).Find(&result).Error; err != nil {
+			t.Fatalf("q=%q: %v", tc.term, err)
+		}
+		if len(result) != tc.want {
+			t.Fatalf("q=%q: expected %d rows, got %+v", tc.term, tc.want, result)
+		}
+	}
+}
+
+func TestApplyQuickSearch_NoMatchableField(t *testing.T) {
+	db := setupTestDB(t)
+	opts := quickSearchTestOptions().WithQuickSearchFields("age")
+
+	var result []TestModel
+	if err := ApplyQuickSearch(db.Model(&TestModel{}), "abc", opts).Find(&result).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(result) != 0 {
+		t.Fatalf("expected no rows, got %+v", result)
+	}
+}
+
+func TestParseQueryWithOptions_QuickSearchAndFilters(t *testing.T) {
+	db := setupTestDB(t)
+	opts := quickSearchTestOptions()
+
+	values := url.Values{}
+	values.Set("q", "test")
+	values.Set("filter[age:gt]", "26")
+
+	q, err := ParseQueryWithOptions(values, opts)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if q.Query != "test" {
+		t.Fatalf("expected query %q, got %q", "test", q.Query)
+	}
+
+	var result []TestModel
+	if err := ApplyWithOptions(db.Model(&TestModel{}), q, opts).Find(&result).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(result) != 1 || result[0].Name != "Alice" {
+		t.Fatalf("expected Alice, got %+v", result)
+	}
+}
+
+func TestApplyQuickSearch_IgnoresTriStateTerms(t *testing.T) {
+	db := setupFlagDB(t)
+	opts := NewOptions([]string{"verified"}).
+		WithFieldTypes(map[string]FieldType{"verified": FieldTypeBool}).
+		WithQuickSearchFields("verified")
+
+	for term, want := range map[string]string{"yes": "1", "any": "", "null": ""} {
+		if got := flagIDs(t, ApplyQuickSearch(db.Model(&flagRow{}), term, opts)); got != want {
+			t.Fatalf("q=%q: got %q, want %q", term, got, want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// RedactMode selects how a redacted field is rendered in results.
+type RedactMode string
+
+const (
+	// RedactHide removes the field from results.
+	RedactHide RedactMode = "hide"
+	// RedactMask replaces the value with '*', keeping KeepPrefix/KeepSuffix characters.
+	RedactMask RedactMode = "mask"
+	// RedactHash replaces the value with its hex SHA-256 (HMAC-SHA-256 when Key is set).
+	RedactHash RedactMode = "hash"
+)
+
+// Redaction is a per-field result redaction (see Options.Redactions and Permissions.Redactions).
+//
+// Redacted fields are removed from the filter, sort, group, aggregate and quick search allowlists
+// (client use is rejected with ErrFieldDenied), so values cannot be inferred by probing with LIKE.
+// They stay selectable; selected values are redacted.
+type Redaction struct {
+	Mode RedactMode
+
+	// KeepPrefix and KeepSuffix are the number of characters left unmasked by RedactMask,
+	// e.g. {KeepPrefix: 1, KeepSuffix: 4} renders "alice@test.com" as "a*********.com".
+	KeepPrefix int
+	KeepSuffix int
+
+	// Key, if set, makes RedactHash an HMAC so hashes cannot be reversed by dictionary lookup.
+	Key []byte
+}
+
+func (r Redaction) validate(field string) error {
+	switch r.Mode {
+	case RedactHide, RedactHash:
+	case RedactMask:
+		if r.KeepPrefix < 0 || r.KeepSuffix < 0 {
+			return fmt.Errorf("redaction for %q: keep counts must be >= 0", field)
+		}
+	default:
+		return fmt.Errorf("redaction for %q: unknown mode %q", field, r.Mode)
+	}
+	if !safeFieldRe.MatchString(field) {
+		return fmt.Errorf("redaction field contains invalid characters: %q", field)
+	}
+	return nil
+}
+
+// apply returns the redacted form of value. Nil values stay nil.
+func (r Redaction) apply(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	s := fmt.Sprint(value)
+	switch r.Mode {
+	case RedactMask:
+		runes := []rune(s)
+		if r.KeepPrefix+r.KeepSuffix >= len(runes) {
+			return strings.Repeat("*", len(runes))
+		}
+		masked := len(runes) - r.KeepPrefix - r.KeepSuffix
+		return string(runes[:r.KeepPrefix]) + strings.Repeat("*", masked) + string(runes[len(runes)-r.KeepSuffix:])
+	case RedactHash:
+		if len(r.Key) > 0 {
+			mac := hmac.New(sha256.New, r.Key)
+			mac.Write([]byte(s))
+			return hex.EncodeToString(mac.Sum(nil))
+		}
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	default:
+		return nil
+	}
+}
+
+// Redact applies the snapshot's redactions to results returned by Find: either []map[string]interface{}
+// (projected rows keyed by column) or a slice of model structs. Struct results are converted to
+// JSON-shaped maps so hidden fields can be dropped; model is used to map columns to their JSON
+// location, nested for gorm:"embedded" struct fields. Models whose JSON encoding cannot be followed
+// (a MarshalJSON on the model or an embedded struct) are an error rather than left unredacted.
+// Without redactions results are returned unchanged. Preloaded associations are not redacted.
+func (s *Snapshot) Redact(db *gorm.DB, model interface{}, results interface{}) (interface{}, error) {
+	if s.opts == nil || len(s.opts.Redactions) == 0 {
+		return results, nil
+	}
+
+	if rows, ok := results.([]map[string]interface{}); ok {
+		for _, row := range rows {
+			redactRow(row, s.opts.Redactions)
+		}
+		return rows, nil
+	}
+
+	paths, err := redactionJSONPaths(db, model, s.opts.Redactions)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var rows []map[string]interface{}
+	if err := dec.Decode(&rows); err != nil {
+		return nil, fmt.Errorf("redaction requires object results: %w", err)
+	}
+	for _, row := range rows {
+		for _, p := range paths {
+			if err := p.redact(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rows, nil
+}
+
+func redactRow(row map[string]interface{}, redactions map[string]Redaction) {
+	for key, r := range redactions {
+		v, ok := row[key]
+		if !ok {
+			continue
+		}
+		if r.Mode == RedactHide {
+			delete(row, key)
+			continue
+		}
+		row[key] = r.apply(v)
+	}
+}
+
+// redactionPath is the JSON location of a redacted column in marshaled model results.
+type redactionPath struct {
+	keys []jsonKey
+	r    Redaction
+}
+
+// jsonKey is an object key on a redactionPath; omitEmpty keys may be missing from a row.
+type jsonKey struct {
+	name      string
+	omitEmpty bool
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// redactionJSONPaths maps redactions from column names to their JSON location in the model, following
+// the struct path GORM recorded for the column (StructField.Index) the way encoding/json does:
+// named embedded structs nest, Go-embedded ones are flattened, and json tags rename or drop fields.
+func redactionJSONPaths(db *gorm.DB, model interface{}, redactions map[string]Redaction) ([]redactionPath, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse gorm model: %w", err)
+	}
+	out := make([]redactionPath, 0, len(redactions))
+	for _, column := range sortedKeys(redactions) {
+		f := lookupColumn(stmt.Schema, column)
+		if f == nil {
+			return nil, fmt.Errorf("redaction: unknown column %q on %s", column, stmt.Schema.Name)
+		}
+		keys, err := jsonKeys(stmt.Schema.ModelType, f.StructField.Index)
+		if err != nil {
+			return nil, fmt.Errorf("redaction for %q: %w", column, err)
+		}
+		if keys != nil {
+			out = append(out, redactionPath{keys: keys, r: redactions[column]})
+		}
+	}
+	return out, nil
+}
+
+// jsonKeys returns the JSON object keys leading to the struct field at index (GORM encodes
+// embedded pointer steps as -i-1), or nil if encoding/json omits the field.
+func jsonKeys(t reflect.Type, index []int) ([]jsonKey, error) {
+	var keys []jsonKey
+	for _, i := range index {
+		if i < 0 {
+			i = -i - 1
+		}
+		t = indirectType(t)
+		if reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+			return nil, fmt.Errorf("%s has its own JSON encoding", t)
+		}
+
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if tag == "-" {
+			return nil, nil
+		}
+		t = sf.Type
+		if sf.Anonymous && name == "" && indirectType(t).Kind() == reflect.Struct {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		keys = append(keys, jsonKey{name: name, omitEmpty: strings.Contains(","+opts+",", ",omitempty,")})
+	}
+	return keys, nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// redact applies p to a decoded row. Nil embedded structs hold nothing to redact; any other
+// missing key means the row is not shaped as expected and is an error.
+func (p redactionPath) redact(row map[string]interface{}) error {
+	m := row
+	for i, k := range p.keys {
+		v, ok := m[k.name]
+		if !ok {
+			if k.omitEmpty {
+				return nil
+			}
+			return fmt.Errorf("redaction: %s not found in results", p)
+		}
+		if i == len(p.keys)-1 {
+			if p.r.Mode == RedactHide {
+				delete(m, k.name)
+			} else {
+				m[k.name] = p.r.apply(v)
+			}
+			return nil
+		}
+		if v == nil {
+			return nil
+		}
+		if m, ok = v.(map[string]interface{}); !ok {
+			return fmt.Errorf("redaction: %s not found in results", p)
+		}
+	}
+	return nil
+}
+
+func (p redactionPath) String() string {
+	names := make([]string, len(p.keys))
+	for i, k := range p.keys {
+		names[i] = k.name
+	}
+	return strconv.Quote(strings.Join(names, "."))
+}
@@ -0,0 +1,224 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func redactTestOptions() *Options {
+	return NewOptions([]string{"name", "email", "age"}).
+		WithSelectableFields("name", "email").
+		WithRedaction("email", Redaction{Mode: RedactMask, KeepPrefix: 1, KeepSuffix: 4}).
+		WithPolicy(func(ctx context.Context) (*Permissions, error) {
+			if ctx.Value("role") == "admin" {
+				return &Permissions{Redactions: map[string]Redaction{}}, nil
+			}
+			return nil, nil
+		})
+}
+
+func redactTestRouter(t *testing.T) *gin.Engine {
+	db := setupTestDB(t)
+	opts := redactTestOptions()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("role", c.GetHeader("X-Role"))
+	})
+	router.GET("/test", SearchHandlerWithOptions[TestModel](db, TestModel{}, opts))
+	router.POST("/test", AdvancedSearchHandlerWithOptions[TestModel](db, TestModel{}, opts))
+	return router
+}
+
+func getRows(t *testing.T, router *gin.Engine, role, target string) []map[string]interface{} {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", target, nil)
+	req.Header.Set("X-Role", role)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return rows
+}
+
+func TestRedaction_MaskPerRole(t *testing.T) {
+	router := redactTestRouter(t)
+
+	rows := getRows(t, router, "user", "/test?filter[name]=Alice")
+	if len(rows) != 1 || rows[0]["Email"] != "a*********.com" || rows[0]["Name"] != "Alice" {
+		t.Fatalf("expected masked email, got %+v", rows)
+	}
+
+	rows = getRows(t, router, "user", "/test?fields=name,email&filter[name]=Alice")
+	if len(rows) != 1 || rows[0]["email"] != "a*********.com" {
+		t.Fatalf("expected masked projected email, got %+v", rows)
+	}
+
+	rows = getRows(t, router, "admin", "/test?filter[name]=Alice")
+	if len(rows) != 1 || rows[0]["Email"] != "alice@test.com" {
+		t.Fatalf("admin should see raw email, got %+v", rows)
+	}
+}
+
+func TestRedaction_FieldNotFilterable(t *testing.T) {
+	router := redactTestRouter(t)
+
+	// GET: the probe is dropped, so both rows come back.
+	if rows := getRows(t, router, "user", "/test?filter[email:like]=alice"); len(rows) != 2 {
+		t.Fatalf("expected redacted filter to be ignored, got %d rows", len(rows))
+	}
+
+	body := `{"filters": {"and": [{"filter": {"field": "email", "op": "like", "value": "alice"}}]}}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/test", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Role", "user")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+}
+
+func TestRedaction_HideAndHash(t *testing.T) {
+	key := []byte("secret")
+	opts := NewOptions([]string{"name"}).
+		WithRedaction("email", Redaction{Mode: RedactHide}).
+		WithRedaction("name", Redaction{Mode: RedactHash, Key: key})
+	s, err := Compile(opts)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	r, err := s.Resolve(context.Background())
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if again, _ := s.Resolve(context.Background()); again != r {
+		t.Fatalf("expected the redacted view to be compiled once and reused")
+	}
+	s = r
+
+	db := setupTestDB(t)
+	out, err := s.Redact(db, &TestModel{}, []TestModel{{Name: "Alice", Email: "alice@test.com"}})
+	if err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+	rows := out.([]map[string]interface{})
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("Alice"))
+	if rows[0]["Name"] != hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("expected HMAC of name, got %v", rows[0]["Name"])
+	}
+	if _, ok := rows[0]["Email"]; ok {
+		t.Fatalf("expected email to be hidden, got %+v", rows[0])
+	}
+}
+
+func TestOptionsConfig_Redactions(t *testing.T) {
+	cfg, err := ParseOptionsYAML([]byte("allowed_fields: [name]\nredactions:\n  name: {mode: mask, keep_prefix: 1}\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	opts, err := cfg.Build(openTagTestDB(t), &inferModel{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if r := opts.Redactions["name"]; r.Mode != RedactMask || r.KeepPrefix != 1 {
+		t.Fatalf("unexpected redaction: %+v", r)
+	}
+
+	cfg, _ = ParseOptionsYAML([]byte("allowed_fields: [name]\nredactions:\n  name: {mode: blur}\n"))
+	if _, err := cfg.Build(openTagTestDB(t), &inferModel{}); err == nil {
+		t.Fatal("expected unknown mode error")
+	}
+}
+
+type redactContact struct {
+	Email string
+	Phone string `json:"phone,omitempty"`
+}
+
+type redactCustomer struct {
+	ID      uint
+	Name    string
+	Contact redactContact `gorm:"embedded"`
+	Billing redactContact `gorm:"embedded;embeddedPrefix:billing_" json:"billing"`
+}
+
+type redactOpaque struct {
+	ID    uint
+	Email string
+}
+
+func (o redactOpaque) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"mail": o.Email})
+}
+
+func redactWith(t *testing.T, field string, r Redaction) *Snapshot {
+	t.Helper()
+	s, err := Compile(NewOptions([]string{"name"}).WithRedaction(field, r))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if s, err = s.Resolve(context.Background()); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	return s
+}
+
+func TestRedaction_EmbeddedStructs(t *testing.T) {
+	db := setupTestDB(t)
+	customers := []redactCustomer{{
+		Name:    "Alice",
+		Contact: redactContact{Email: "alice@test.com", Phone: "555-0100"},
+		Billing: redactContact{Email: "billing@test.com"},
+	}}
+
+	out, err := redactWith(t, "email", Redaction{Mode: RedactHide}).Redact(db, &redactCustomer{}, customers)
+	if err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+	row := out.([]map[string]interface{})[0]
+	contact := row["Contact"].(map[string]interface{})
+	if _, ok := contact["Email"]; ok || contact["phone"] != "555-0100" {
+		t.Fatalf("expected the embedded email to be hidden, got %+v", row)
+	}
+	if row["billing"].(map[string]interface{})["Email"] != "billing@test.com" {
+		t.Fatalf("expected the billing email to be kept, got %+v", row)
+	}
+
+	out, err = redactWith(t, "billing_email", Redaction{Mode: RedactMask, KeepSuffix: 4}).Redact(db, &redactCustomer{}, customers)
+	if err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+	row = out.([]map[string]interface{})[0]
+	if got := row["billing"].(map[string]interface{})["Email"]; got != "************.com" {
+		t.Fatalf("expected the prefixed embedded email to be masked, got %v", got)
+	}
+
+	// An omitted empty value holds nothing to redact.
+	if _, err := redactWith(t, "phone", Redaction{Mode: RedactHide}).Redact(db, &redactCustomer{}, []redactCustomer{{Name: "Bob"}}); err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+}
+
+func TestRedaction_CustomJSONIsAnError(t *testing.T) {
+	db := setupTestDB(t)
+	_, err := redactWith(t, "email", Redaction{Mode: RedactHide}).Redact(db, &redactOpaque{}, []redactOpaque{{Email: "alice@test.com"}})
+	if err == nil {
+		t.Fatal("expected an error for a model with its own JSON encoding")
+	}
+}
@@ -0,0 +1,156 @@
+package go_dbsearch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Registry holds compiled Snapshots keyed by resource name (e.g. "users", "orders").
+//
+// Reads are lock-free; Register/Set/Reload atomically swap the snapshot for a name, so handlers
+// created with the *FromRegistry constructors keep serving while options are reloaded.
+type Registry struct {
+	mu        sync.Mutex // serializes writers
+	snapshots atomic.Pointer[map[string]*Snapshot]
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	r := &Registry{}
+	m := map[string]*Snapshot{}
+	r.snapshots.Store(&m)
+	return r
+}
+
+// Register compiles opts and stores it under name, replacing any previous snapshot.
+func (r *Registry) Register(name string, opts *Options) error {
+	s, err := Compile(opts)
+	if err != nil {
+		return fmt.Errorf("register %q: %w", name, err)
+	}
+	r.Set(name, s)
+	return nil
+}
+
+// Set stores a compiled snapshot under name, replacing any previous snapshot.
+func (r *Registry) Set(name string, s *Snapshot) {
+	r.update(func(m map[string]*Snapshot) { m[name] = s })
+}
+
+// Delete removes name from the registry.
+func (r *Registry) Delete(name string) {
+	r.update(func(m map[string]*Snapshot) { delete(m, name) })
+}
+
+// Get returns the current snapshot for name.
+func (r *Registry) Get(name string) (*Snapshot, error) {
+	if s, ok := (*r.snapshots.Load())[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("dbsearch: no options registered for %q", name)
+}
+
+// Names returns the registered names in sorted order.
+func (r *Registry) Names() []string {
+	return sortedKeys(*r.snapshots.Load())
+}
+
+// Reload calls load, compiles the result and swaps it in under name.
+// If load or compilation fails, the previous snapshot keeps serving and the error is returned.
+func (r *Registry) Reload(name string, load func() (*Options, error)) error {
+	opts, err := load()
+	if err != nil {
+		return fmt.Errorf("reload %q: %w", name, err)
+	}
+	return r.Register(name, opts)
+}
+
+func (r *Registry) update(fn func(m map[string]*Snapshot)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := *r.snapshots.Load()
+	next := make(map[string]*Snapshot, len(old)+1)
+	for k, v := range old {
+		next[k] = v
+	}
+	fn(next)
+	r.snapshots.Store(&next)
+}
+
+// source returns a snapshotSource reading name on every call.
+func (r *Registry) source(name string) snapshotSource {
+	return func() (*Snapshot, error) { return r.Get(name) }
+}
+
+// ReloadOnSignal calls reload each time one of sigs (SIGHUP by default) is received, until ctx is done.
+func ReloadOnSignal(ctx context.Context, reload func(), sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				reload()
+			}
+		}
+	}()
+}
+
+// ReloadOnFileChange polls the modification time of the given files every interval and calls
+// reload when any of them changes, until ctx is done.
+func ReloadOnFileChange(ctx context.Context, interval time.Duration, reload func(), paths ...string) {
+	modTimes := func() []time.Time {
+		out := make([]time.Time, len(paths))
+		for i, p := range paths {
+			if fi, err := os.Stat(p); err == nil {
+				out[i] = fi.ModTime()
+			}
+		}
+		return out
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := modTimes()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cur := modTimes()
+				for i := range cur {
+					if !cur[i].Equal(last[i]) {
+						last = cur
+						reload()
+						break
+					}
+				}
+			}
+		}
+	}()
+}
+
+// snapshotSource yields the snapshot a handler should use for the current request.
+type snapshotSource func() (*Snapshot, error)
+
+// optionsSource builds a snapshot around opts for every request, so handlers created with
+// *WithOptions constructors keep reading opts as they always have.
+func optionsSource(opts *Options) snapshotSource {
+	return func() (*Snapshot, error) { return newSnapshot(opts) }
+}
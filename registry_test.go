@@ -0,0 +1,173 @@
+package go_dbsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompile_IsolatedFromSource(t *testing.T) {
+	opts := NewOptions([]string{"name"}).WithFieldTypes(map[string]FieldType{"name": FieldTypeString})
+	s, err := Compile(opts)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	opts.AllowedFields["age"] = struct{}{}
+	opts.FieldTypes["name"] = FieldTypeInt
+
+	if err := s.Validator().ValidateField("age"); err == nil {
+		t.Fatalf("snapshot must not see fields added after Compile")
+	}
+	if v, err := s.Caster().CastFromString("name", "x"); err != nil || v != "x" {
+		t.Fatalf("snapshot must keep its field types, got %v %v", v, err)
+	}
+	if _, err := Compile(NewOptions(nil)); err == nil {
+		t.Fatalf("expected error for empty allowlist")
+	}
+}
+
+func TestRegistry_SwapAndReload(t *testing.T) {
+	reg := NewRegistry()
+	if _, err := reg.Get("users"); err == nil {
+		t.Fatalf("expected error for unknown name")
+	}
+	if err := reg.Register("users", NewOptions([]string{"name"})); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	failing := func() (*Options, error) { return nil, errors.New("boom") }
+	if err := reg.Reload("users", failing); err == nil {
+		t.Fatalf("expected reload error")
+	}
+	s, _ := reg.Get("users")
+	if err := s.Validator().ValidateField("name"); err != nil {
+		t.Fatalf("previous snapshot must keep serving: %v", err)
+	}
+
+	if err := reg.Reload("users", func() (*Options, error) { return NewOptions([]string{"age"}), nil }); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	s, _ = reg.Get("users")
+	if err := s.Validator().ValidateField("age"); err != nil {
+		t.Fatalf("reloaded snapshot not visible: %v", err)
+	}
+	if names := reg.Names(); len(names) != 1 || names[0] != "users" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+	reg.Delete("users")
+	if len(reg.Names()) != 0 {
+		t.Fatalf("expected empty registry")
+	}
+}
+
+func TestSearchHandlerFromRegistry_ConcurrentSwap(t *testing.T) {
+	db := setupTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	reg := NewRegistry()
+	byName := NewOptions([]string{"name"})
+	byAge := NewOptions([]string{"age"})
+	if err := reg.Register("users", byName); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/users", SearchHandlerFromRegistry[TestModel](db, TestModel{}, reg, "users"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("GET", "/users?filter[name:eq]=Alice", nil)
+				router.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					t.Errorf("Expected 200 OK, got %d", w.Code)
+				}
+			}
+		}()
+	}
+	for j := 0; j < 20; j++ {
+		if j%2 == 0 {
+			_ = reg.Register("users", byAge)
+		} else {
+			_ = reg.Register("users", byName)
+		}
+	}
+	wg.Wait()
+
+	_ = reg.Register("users", byAge)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users?filter[name:eq]=Alice", nil)
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "Bob") {
+		t.Fatalf("name filter should be ignored after swap, got %s", w.Body.String())
+	}
+}
+
+func TestSearchHandlerFromRegistry_Status(t *testing.T) {
+	db := setupTestDB(t)
+	reg := NewRegistry()
+	if err := reg.Register("users", NewOptions([]string{"name"}).WithRequestTimezone(true)); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/users", SearchHandlerFromRegistry[TestModel](db, TestModel{}, reg, "users"))
+	router.GET("/missing", SearchHandlerFromRegistry[TestModel](db, TestModel{}, reg, "missing"))
+
+	for path, want := range map[string]int{
+		"/missing":             http.StatusInternalServerError,
+		"/users?tz=Nowhere/At": http.StatusBadRequest,
+		"/users":               http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d: %s", path, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestReloadOnFileChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan struct{}, 1)
+	ReloadOnFileChange(ctx, 5*time.Millisecond, func() {
+		select {
+		case reloaded <- struct{}{}:
+		default:
+		}
+	}, path)
+
+	time.Sleep(20 * time.Millisecond)
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("reload was not triggered")
+	}
+}
@@ -0,0 +1,142 @@
+package go_dbsearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Relative date expressions are accepted wherever date, calendar date and time values are
+// (scalar comparisons, IN and BETWEEN, query string and JSON). An expression is an anchor followed
+// by any number of steps:
+//
+//	anchor: now | today | yesterday | tomorrow | startOfDay | startOfWeek | startOfMonth | startOfYear
+//	step:   +N<unit> | -N<unit> | /<unit>   (add, subtract, round down)
+//	unit:   s | m | h | d | w | M | y       (m is minutes, M is months)
+//
+// A leading step without an anchor is relative to now: "-30m" is "now-30m". Examples: "now-7d",
+// "now/d" (start of today), "startOfMonth-1M" (start of last month). Weeks start on Monday.
+// Anchors are case-insensitive and resolved in the caster's location with Options.Clock, so they
+// stay relative for saved queries.
+
+// relativeAnchors rounds now to each anchor.
+var relativeAnchors = map[string]func(now time.Time) time.Time{
+	"now":          func(now time.Time) time.Time { return now },
+	"today":        func(now time.Time) time.Time { return truncateTime(now, 'd') },
+	"yesterday":    func(now time.Time) time.Time { return truncateTime(now, 'd').AddDate(0, 0, -1) },
+	"tomorrow":     func(now time.Time) time.Time { return truncateTime(now, 'd').AddDate(0, 0, 1) },
+	"startofday":   func(now time.Time) time.Time { return truncateTime(now, 'd') },
+	"startofweek":  func(now time.Time) time.Time { return truncateTime(now, 'w') },
+	"startofmonth": func(now time.Time) time.Time { return truncateTime(now, 'M') },
+	"startofyear":  func(now time.Time) time.Time { return truncateTime(now, 'y') },
+}
+
+// isRelativeTime reports whether s looks like a relative expression rather than a literal date
+// (literal dates and timestamps start with a digit).
+func isRelativeTime(s string) bool {
+	if s == "" {
+		return false
+	}
+	c := s[0]
+	return c == '+' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// evalRelativeTime evaluates expr against now (already in the target location).
+func evalRelativeTime(expr string, now time.Time) (time.Time, error) {
+	// An unescaped "+" in a query string arrives as a space ("now+1d" -> "now 1d").
+	rest := strings.ReplaceAll(expr, " ", "+")
+	i := strings.IndexAny(rest, "+-/")
+	if i < 0 {
+		i = len(rest)
+	}
+	t := now
+	if anchor := rest[:i]; anchor != "" {
+		round, ok := relativeAnchors[strings.ToLower(anchor)]
+		if !ok {
+			return time.Time{}, fmt.Errorf("unknown date anchor %q in %q", anchor, expr)
+		}
+		t = round(now)
+	}
+	rest = rest[i:]
+
+	for rest != "" {
+		op := rest[0]
+		rest = rest[1:]
+		if op == '/' {
+			if rest == "" || !isTimeUnit(rest[0]) {
+				return time.Time{}, fmt.Errorf("invalid rounding unit in %q", expr)
+			}
+			t = truncateTime(t, rest[0])
+			rest = rest[1:]
+			continue
+		}
+		if op != '+' && op != '-' {
+			return time.Time{}, fmt.Errorf("unexpected %q in %q", op, expr)
+		}
+		j := 0
+		for j < len(rest) && rest[j] >= '0' && rest[j] <= '9' {
+			j++
+		}
+		if j == 0 || j == len(rest) || !isTimeUnit(rest[j]) {
+			return time.Time{}, fmt.Errorf("invalid offset in %q (want e.g. -7d)", expr)
+		}
+		n, err := strconv.Atoi(rest[:j])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid offset in %q: %w", expr, err)
+		}
+		if op == '-' {
+			n = -n
+		}
+		t = addTime(t, n, rest[j])
+		rest = rest[j+1:]
+	}
+	return t, nil
+}
+
+func isTimeUnit(u byte) bool {
+	return strings.IndexByte("smhdwMy", u) >= 0
+}
+
+// addTime adds n units to t. Calendar units use AddDate so days stay aligned across DST changes.
+func addTime(t time.Time, n int, unit byte) time.Time {
+	switch unit {
+	case 's':
+		return t.Add(time.Duration(n) * time.Second)
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute)
+	case 'h':
+		return t.Add(time.Duration(n) * time.Hour)
+	case 'd':
+		return t.AddDate(0, 0, n)
+	case 'w':
+		return t.AddDate(0, 0, 7*n)
+	case 'M':
+		return t.AddDate(0, n, 0)
+	default: // 'y'
+		return t.AddDate(n, 0, 0)
+	}
+}
+
+// truncateTime rounds t down to the start of unit in t's location.
+func truncateTime(t time.Time, unit byte) time.Time {
+	y, mo, d := t.Date()
+	loc := t.Location()
+	switch unit {
+	case 's':
+		return time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), 0, loc)
+	case 'm':
+		return time.Date(y, mo, d, t.Hour(), t.Minute(), 0, 0, loc)
+	case 'h':
+		return time.Date(y, mo, d, t.Hour(), 0, 0, 0, loc)
+	case 'd':
+		return time.Date(y, mo, d, 0, 0, 0, 0, loc)
+	case 'w':
+		offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+		return time.Date(y, mo, d-offset, 0, 0, 0, 0, loc)
+	case 'M':
+		return time.Date(y, mo, 1, 0, 0, 0, 0, loc)
+	default: // 'y'
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+	}
+}
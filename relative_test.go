@@ -0,0 +1,95 @@
+package go_dbsearch
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestEvalRelativeTime(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2024, 3, 13, 15, 45, 30, 0, time.UTC)
+	cases := map[string]time.Time{
+		"now":             now,
+		"now-7d":          now.AddDate(0, 0, -7),
+		"-30m":            now.Add(-30 * time.Minute),
+		"+2h":             now.Add(2 * time.Hour),
+		"now/d":           time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC),
+		"now-1d/d":        time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC),
+		"today":           time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC),
+		"Yesterday":       time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC),
+		"tomorrow":        time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC),
+		"startOfWeek":     time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+		"startOfMonth":    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		"startOfMonth-1M": time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"startofyear":     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"now+1w/M":        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		"now 1h":          now.Add(time.Hour),
+	}
+	for expr, want := range cases {
+		got, err := evalRelativeTime(expr, now)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("%s: got %v, want %v", expr, got, want)
+		}
+	}
+
+	for _, expr := range []string{"later", "now-", "now-7", "now-7x", "now/", "now/q", "now*2d"} {
+		if _, err := evalRelativeTime(expr, now); err == nil {
+			t.Fatalf("%s: expected error", expr)
+		}
+	}
+}
+
+func TestParseQuery_RelativeDates(t *testing.T) {
+	tehran := mustLoadLocation(t, "Asia/Tehran")
+	// 22:00 UTC is already the next day in Tehran.
+	now := time.Date(2024, 3, 13, 22, 0, 0, 0, time.UTC)
+	opts := NewOptions([]string{"day", "at"}).
+		WithFieldTypes(map[string]FieldType{"day": FieldTypeDate, "at": FieldTypeTime}).
+		WithLocation(tehran).
+		WithClock(func() time.Time { return now })
+
+	values := url.Values{}
+	values.Set("filter[day:between]", "now-7d,today")
+	values.Set("filter[at:gte]", "-30m")
+	values.Set("filter[day:in]", "today,yesterday")
+	q, err := ParseQueryWithOptions(values, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Filters) != 3 {
+		t.Fatalf("expected 3 filters, got %#v", q.Filters)
+	}
+
+	today := time.Date(2024, 3, 14, 0, 0, 0, 0, tehran)
+	for _, f := range q.Filters {
+		switch f.Op {
+		case "BETWEEN":
+			r := f.Value.(Range)
+			if !r.From.(time.Time).Equal(today.AddDate(0, 0, -7)) || !r.To.(time.Time).Equal(today.AddDate(0, 0, 1)) {
+				t.Fatalf("between: got %v", r)
+			}
+		case ">=":
+			if !f.Value.(time.Time).Equal(now.Add(-30 * time.Minute)) {
+				t.Fatalf("gte: got %v", f.Value)
+			}
+		case "IN":
+			in := f.Value.([]interface{})
+			if !in[0].(time.Time).Equal(today) || !in[1].(time.Time).Equal(today.AddDate(0, 0, -1)) {
+				t.Fatalf("in: got %v", in)
+			}
+		default:
+			t.Fatalf("unexpected filter %#v", f)
+		}
+	}
+
+	values = url.Values{}
+	values.Set("filter[day:eq]", "someday")
+	opts.StrictJSON = false
+	if q, err = ParseQueryWithOptions(values, opts); err != nil || len(q.Filters) != 0 {
+		t.Fatalf("invalid expression should be skipped, got %#v, %v", q.Filters, err)
+	}
+}
@@ -0,0 +1,99 @@
+package go_dbsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrScope wraps errors returned by Options.Scopes, and reports queries built from a snapshot whose
+// scopes were never resolved.
+var ErrScope = errors.New("search scope could not be resolved")
+
+// ScopeFunc returns mandatory filters for the request context, e.g. tenant_id from a JWT.
+//
+// Scope filters are trusted (they need not be allowlisted) and are ANDed outside the client's
+// filters, so a client OR cannot escape them. Returning an error rejects the request (HTTP 403).
+type ScopeFunc func(ctx context.Context) ([]Filter, error)
+
+// withScopes resolves Options.Scopes for ctx and returns a snapshot that applies them.
+// Client filters on scoped fields are rejected with ErrFieldDenied.
+func (s *Snapshot) withScopes(ctx context.Context) (*Snapshot, error) {
+	if s.opts == nil || len(s.opts.Scopes) == 0 {
+		return s, nil
+	}
+
+	var filters []Filter
+	scoped := map[string]struct{}{}
+	for _, scope := range s.opts.Scopes {
+		if scope == nil {
+			continue
+		}
+		fs, err := scope(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrScope, err)
+		}
+		for _, f := range fs {
+			f.Field = strings.TrimSpace(f.Field)
+			if f.Field == "" || !safeFieldRe.MatchString(f.Field) {
+				return nil, fmt.Errorf("%w: invalid scope field %q", ErrScope, f.Field)
+			}
+			op, err := ValidateOperator(f.Op)
+			if err != nil {
+				return nil, fmt.Errorf("%w: scope on %q: %v", ErrScope, f.Field, err)
+			}
+			f.Op = op
+			if (op == "NEAR" || op == "BBOX") && s.caster != nil {
+				switch f.Value.(type) {
+				case GeoNear, GeoBox:
+				default:
+					if f.Value, err = s.caster.normalizeGeo(f.Field, op, f.Value); err != nil {
+						return nil, fmt.Errorf("%w: scope on %q: %v", ErrScope, f.Field, err)
+					}
+				}
+			}
+			if _, ok := f.Value.(matchAny); ok {
+				return nil, fmt.Errorf("%w: scope on %q matches any value", ErrScope, f.Field)
+			}
+			if err := checkFilterValue(op, f.Value); err != nil {
+				return nil, fmt.Errorf("%w: scope on %q: %v", ErrScope, f.Field, err)
+			}
+			filters = append(filters, f)
+			scoped[f.Field] = struct{}{}
+		}
+	}
+
+	out := *s
+	out.scopes = filters
+	out.scopesResolved = true
+	if s.validator != nil {
+		v := *s.validator
+		v.scoped = scoped
+		out.validator = &v
+	}
+	return &out, nil
+}
+
+// ApplyScopes applies the snapshot's resolved scope filters to db.
+//
+// Handlers and Apply call it already; use it when building queries with lower-level functions
+// such as ApplyAggregate or ApplyFacet. If Options.Scopes is set but the snapshot was not obtained
+// from Resolve, ErrScope is added to db so the query fails instead of leaking rows.
+func (s *Snapshot) ApplyScopes(db *gorm.DB) *gorm.DB {
+	if s.opts != nil && len(s.opts.Scopes) > 0 && !s.scopesResolved {
+		_ = db.AddError(fmt.Errorf("%w: snapshot was not resolved for a request context", ErrScope))
+		return db
+	}
+	for _, f := range s.scopes {
+		// Resolve already checked the values; never let a scope that cannot be rendered widen the query.
+		if err := checkFilterValue(f.Op, f.Value); err != nil {
+			_ = db.AddError(fmt.Errorf("%w: scope on %q: %v", ErrScope, f.Field, err))
+			return db.Where("1 = 0")
+		}
+		db = f.Apply(db)
+	}
+	return db
+}
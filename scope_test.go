@@ -0,0 +1,181 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type tenantDoc struct {
+	ID       uint
+	TenantID int
+	Title    string
+}
+
+func setupScopeTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&tenantDoc{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db.Create(&[]tenantDoc{
+		{TenantID: 1, Title: "a"},
+		{TenantID: 1, Title: "b"},
+		{TenantID: 2, Title: "a"},
+		{TenantID: 2, Title: "c"},
+	})
+	return db
+}
+
+func tenantScope(ctx context.Context) ([]Filter, error) {
+	tenant, ok := ctx.Value("tenant").(int)
+	if !ok {
+		return nil, errors.New("missing tenant")
+	}
+	return []Filter{{Field: "tenant_id", Op: "eq", Value: tenant}}, nil
+}
+
+func scopeTestRouter(db *gorm.DB, opts *Options) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if tenant, err := strconv.Atoi(c.GetHeader("X-Tenant")); err == nil {
+			c.Set("tenant", tenant)
+		}
+	})
+	router.GET("/docs", SearchHandlerWithOptions[tenantDoc](db, tenantDoc{}, opts))
+	router.POST("/docs/search", AdvancedSearchHandlerWithOptions[tenantDoc](db, tenantDoc{}, opts))
+	return router
+}
+
+func TestScopes_ClientOrCannotEscape(t *testing.T) {
+	db := setupScopeTestDB(t)
+	opts := NewOptions([]string{"title", "tenant_id"}).WithScope(tenantScope)
+	router := scopeTestRouter(db, opts)
+
+	body := `{"filters": {"or": [{"filter": {"field": "title", "op": "eq", "value": "a"}}, {"filter": {"field": "title", "op": "eq", "value": "c"}}]}}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/docs/search", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", "1")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var docs []tenantDoc
+	if err := json.Unmarshal(w.Body.Bytes(), &docs); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(docs) != 1 || docs[0].TenantID != 1 || docs[0].Title != "a" {
+		t.Fatalf("expected only tenant 1 row 'a', got %+v", docs)
+	}
+}
+
+func TestScopes_ScopedFieldAndMissingScope(t *testing.T) {
+	db := setupScopeTestDB(t)
+	opts := NewOptions([]string{"title", "tenant_id"}).WithScope(tenantScope)
+	router := scopeTestRouter(db, opts)
+
+	post := func(tenant, body string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/docs/search", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tenant != "" {
+			req.Header.Set("X-Tenant", tenant)
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	override := `{"filters": {"or": [{"filter": {"field": "tenant_id", "op": "eq", "value": 2}}]}}`
+	if code := post("1", override); code != http.StatusForbidden {
+		t.Fatalf("filter on scoped field: expected 403, got %d", code)
+	}
+	if code := post("", `{}`); code != http.StatusForbidden {
+		t.Fatalf("missing scope: expected 403, got %d", code)
+	}
+
+	// GET stays permissive: the override is dropped, the scope still applies.
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/docs?filter[tenant_id]=2", nil)
+	req.Header.Set("X-Tenant", "1")
+	router.ServeHTTP(w, req)
+	var docs []tenantDoc
+	if err := json.Unmarshal(w.Body.Bytes(), &docs); err != nil {
+		t.Fatalf("decode: %v (%s)", err, w.Body.String())
+	}
+	if len(docs) != 2 || docs[0].TenantID != 1 || docs[1].TenantID != 1 {
+		t.Fatalf("expected tenant 1 rows only, got %+v", docs)
+	}
+}
+
+func TestApplyWithContext_Scopes(t *testing.T) {
+	db := setupScopeTestDB(t)
+	opts := NewOptions([]string{"title"}).WithScope(tenantScope)
+
+	var docs []tenantDoc
+	err := ApplyWithOptions(db.Model(&tenantDoc{}), SearchQuery{}, opts).Find(&docs).Error
+	if !errors.Is(err, ErrScope) {
+		t.Fatalf("ApplyWithOptions must fail closed with scopes, got %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), "tenant", 2) //nolint:staticcheck
+	q, err := ParseQueryWithContext(ctx, url.Values{"filter[title]": {"c"}}, opts)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := ApplyWithContext(ctx, db.Model(&tenantDoc{}), q, opts).Find(&docs).Error; err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(docs) != 1 || docs[0].TenantID != 2 {
+		t.Fatalf("expected one tenant 2 row, got %+v", docs)
+	}
+
+	s, err := Compile(opts)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if err := s.Apply(db.Model(&tenantDoc{}), SearchQuery{}).Find(&docs).Error; !errors.Is(err, ErrScope) {
+		t.Fatalf("unresolved snapshot must fail closed, got %v", err)
+	}
+}
+
+func TestScopes_InvalidValueFailsClosed(t *testing.T) {
+	db := setupScopeTestDB(t)
+	ctx := context.Background()
+	for _, bad := range []Filter{
+		{Field: "tenant_id", Op: "between", Value: []int{1, 1}},
+		{Field: "tenant_id", Op: "near"},
+		{Field: "tenant_id", Op: "bbox", Value: "0,0,1,1"},
+		{Field: "tenant_id", Op: "gt", Value: sqlNull{}},
+		{Field: "tenant_id", Op: "eq", Value: matchAny{}},
+	} {
+		bad := bad
+		opts := NewOptions([]string{"title"}).WithScope(func(context.Context) ([]Filter, error) {
+			return []Filter{bad}, nil
+		})
+		var docs []tenantDoc
+		err := ApplyWithContext(ctx, db.Model(&tenantDoc{}), SearchQuery{}, opts).Find(&docs).Error
+		if !errors.Is(err, ErrScope) || len(docs) != 0 {
+			t.Fatalf("scope %+v: expected ErrScope and no rows, got %v and %d rows", bad, err, len(docs))
+		}
+
+		// A snapshot carrying an unrenderable scope fails at apply time too.
+		s := &Snapshot{opts: opts, scopes: []Filter{{Field: bad.Field, Op: "BETWEEN", Value: bad.Value}}, scopesResolved: true}
+		if err := s.ApplyScopes(db.Model(&tenantDoc{})).Find(&docs).Error; !errors.Is(err, ErrScope) || len(docs) != 0 {
+			t.Fatalf("ApplyScopes %+v: expected ErrScope and no rows, got %v and %d rows", bad, err, len(docs))
+		}
+	}
+}
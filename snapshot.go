@@ -0,0 +1,176 @@
+package go_dbsearch
+
+import (
+	"errors"
+	"net/url"
+
+	"gorm.io/gorm"
+)
+
+// Snapshot is an immutable, compiled view of Options.
+//
+// Compile deep-copies the Options and builds the Validator and ValueCaster once, so a Snapshot
+// is safe for concurrent use and unaffected by later changes to the source Options
+// (including InferFieldTypesFromModel). Use a Registry to swap snapshots at runtime.
+type Snapshot struct {
+	opts      *Options
+	validator *Validator
+	caster    *ValueCaster
+
+	// redacted is the view with Options.Redactions applied, used by Resolve when no Policy
+	// narrows the request. It is nil when there are no redactions.
+	redacted *Snapshot
+
+	// scopes are the mandatory filters resolved for a request (see Resolve).
+	scopes         []Filter
+	scopesResolved bool
+}
+
+// Compile validates opts and returns an immutable Snapshot of it.
+func Compile(opts *Options) (*Snapshot, error) {
+	if opts == nil {
+		return nil, errors.New("options is required (phase-4): AllowedFields must be provided")
+	}
+	return newSnapshot(opts.Clone())
+}
+
+// newSnapshot builds a snapshot around opts without copying it.
+// It is used for per-call APIs that take *Options directly.
+func newSnapshot(opts *Options) (*Snapshot, error) {
+	v, err := NewValidatorFromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{opts: opts, validator: v, caster: NewValueCaster(opts)}
+	if len(opts.Redactions) > 0 {
+		if s.redacted, err = s.restrict(&Permissions{}); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Options returns a copy of the snapshot's Options.
+func (s *Snapshot) Options() *Options {
+	return s.opts.Clone()
+}
+
+// Validator returns the snapshot's Validator.
+func (s *Snapshot) Validator() *Validator {
+	return s.validator
+}
+
+// Caster returns the snapshot's ValueCaster.
+func (s *Snapshot) Caster() *ValueCaster {
+	return s.caster
+}
+
+// ParseQuery is ParseQueryWithOptions using the compiled snapshot.
+func (s *Snapshot) ParseQuery(values url.Values) (SearchQuery, error) {
+	return parseQuery(values, s)
+}
+
+// Apply is ApplyWithOptions using the compiled snapshot. Scopes are applied only if the snapshot
+// was returned by Resolve.
+func (s *Snapshot) Apply(db *gorm.DB, query SearchQuery) *gorm.DB {
+	return applySearch(db, query, s)
+}
+
+// Clone returns a deep copy of o (maps and slices are copied; hook functions are shared).
+func (o *Options) Clone() *Options {
+	if o == nil {
+		return nil
+	}
+	c := *o
+	c.AllowedFields = cloneSet(o.AllowedFields)
+	c.SortableFields = cloneSet(o.SortableFields)
+	c.SelectableFields = cloneSet(o.SelectableFields)
+	c.GroupableFields = cloneSet(o.GroupableFields)
+	c.AggregatableFields = cloneSet(o.AggregatableFields)
+	c.QuickSearchFields = cloneSlice(o.QuickSearchFields)
+	c.Scopes = cloneSlice(o.Scopes)
+
+	if o.FieldTypes != nil {
+		c.FieldTypes = make(map[string]FieldType, len(o.FieldTypes))
+		for k, v := range o.FieldTypes {
+			c.FieldTypes[k] = v
+		}
+	}
+	if o.FieldOperators != nil {
+		c.FieldOperators = make(map[string][]string, len(o.FieldOperators))
+		for k, v := range o.FieldOperators {
+			c.FieldOperators[k] = cloneSlice(v)
+		}
+	}
+	if o.FieldAliases != nil {
+		c.FieldAliases = make(map[string]string, len(o.FieldAliases))
+		for k, v := range o.FieldAliases {
+			c.FieldAliases[k] = v
+		}
+	}
+	if o.Enums != nil {
+		c.Enums = make(map[string]map[string]interface{}, len(o.Enums))
+		for k, v := range o.Enums {
+			values := make(map[string]interface{}, len(v))
+			for label, stored := range v {
+				values[label] = stored
+			}
+			c.Enums[k] = values
+		}
+	}
+	if o.TextNormalization != nil {
+		c.TextNormalization = make(map[string]TextNormalization, len(o.TextNormalization))
+		for k, v := range o.TextNormalization {
+			c.TextNormalization[k] = v
+		}
+	}
+	if o.BoolValues != nil {
+		b := o.BoolValues.clone()
+		c.BoolValues = &b
+	}
+	if o.GeoFields != nil {
+		c.GeoFields = make(map[string]GeoField, len(o.GeoFields))
+		for k, v := range o.GeoFields {
+			c.GeoFields[k] = v
+		}
+	}
+	if o.TimeLayouts != nil {
+		c.TimeLayouts = make(map[string][]string, len(o.TimeLayouts))
+		for k, v := range o.TimeLayouts {
+			c.TimeLayouts[k] = cloneSlice(v)
+		}
+	}
+	if o.Redactions != nil {
+		c.Redactions = make(map[string]Redaction, len(o.Redactions))
+		for k, v := range o.Redactions {
+			v.Key = cloneSlice(v.Key)
+			c.Redactions[k] = v
+		}
+	}
+	if o.Includes != nil {
+		c.Includes = make(map[string]Include, len(o.Includes))
+		for k, v := range o.Includes {
+			v.Fields = cloneSlice(v.Fields)
+			c.Includes[k] = v
+		}
+	}
+	return &c
+}
+
+func cloneSet(m map[string]struct{}) map[string]struct{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]struct{}, len(m))
+	for k := range m {
+		out[k] = struct{}{}
+	}
+	return out
+}
+
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
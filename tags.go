@@ -0,0 +1,142 @@
+package go_dbsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TagName is the struct tag read by OptionsFromModel.
+const TagName = "dbsearch"
+
+// OptionsFromModel builds Options from `dbsearch` struct tags on a GORM model.
+//
+// Tag syntax (comma-separated):
+//
+//	Status    string    `dbsearch:"filter,sort,ops=eq|in"`
+//	CreatedAt time.Time `dbsearch:"filter,sort,alias=createdAt,type=date"`
+//
+// Flags:
+//   - filter:    add to AllowedFields
+//   - sort:      add to SortableFields
+//   - select:    add to SelectableFields
+//   - group:     add to GroupableFields
+//   - aggregate: add to AggregatableFields
+//   - search:    add to QuickSearchFields (requires filter)
+//
+// Settings:
+//   - ops=a|b:   restrict operators (FieldOperators)
+//   - alias=x:   client-facing name (FieldAliases); it must not be another column's name
+//   - type=t:    FieldType override (e.g. date vs time); otherwise inferred from the Go type
+//     (types implementing EnumValuer become enums with their declared values)
+//
+// Fields are keyed by column name. Untagged fields (and `dbsearch:"-"`) stay hidden.
+// Unknown tag items are errors, so typos do not silently expose or hide fields.
+func OptionsFromModel(db *gorm.DB, model any) (*Options, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db is nil")
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse gorm model: %w", err)
+	}
+	if stmt.Schema == nil {
+		return nil, fmt.Errorf("gorm schema is nil after parse")
+	}
+
+	opts := NewOptions(nil)
+	opts.SortableFields = map[string]struct{}{}
+
+	for _, f := range stmt.Schema.Fields {
+		tag, ok := f.Tag.Lookup(TagName)
+		if !ok || strings.TrimSpace(tag) == "-" || f.DBName == "" {
+			continue
+		}
+		column := f.DBName
+
+		var (
+			typeOverride   FieldType
+			filter, search bool
+		)
+		for _, item := range strings.Split(tag, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			key, value, hasValue := strings.Cut(item, "=")
+			key = strings.ToLower(strings.TrimSpace(key))
+			value = strings.TrimSpace(value)
+
+			switch {
+			case !hasValue && key == "filter":
+				filter = true
+				opts.AllowedFields[column] = struct{}{}
+			case !hasValue && key == "sort":
+				opts.SortableFields[column] = struct{}{}
+			case !hasValue && key == "select":
+				opts.SelectableFields = addField(opts.SelectableFields, column)
+			case !hasValue && key == "group":
+				opts.GroupableFields = addField(opts.GroupableFields, column)
+			case !hasValue && key == "aggregate":
+				opts.AggregatableFields = addField(opts.AggregatableFields, column)
+			case !hasValue && key == "search":
+				search = true
+				opts.QuickSearchFields = append(opts.QuickSearchFields, column)
+			case hasValue && key == "ops":
+				ops := strings.Split(value, "|")
+				for _, op := range ops {
+					if _, err := ValidateOperator(op); err != nil {
+						return nil, fmt.Errorf("%s.%s: %w", stmt.Schema.Name, f.Name, err)
+					}
+				}
+				opts.WithFieldOperators(column, ops...)
+			case hasValue && key == "alias":
+				if !aliasRe.MatchString(value) {
+					return nil, fmt.Errorf("%s.%s: invalid alias %q", stmt.Schema.Name, f.Name, value)
+				}
+				if lookupColumn(stmt.Schema, value) != nil {
+					return nil, fmt.Errorf("%s.%s: alias %q is a column name", stmt.Schema.Name, f.Name, value)
+				}
+				if other, dup := opts.FieldAliases[value]; dup {
+					return nil, fmt.Errorf("%s.%s: alias %q is already used by %q", stmt.Schema.Name, f.Name, value, other)
+				}
+				opts.WithFieldAlias(value, column)
+			case hasValue && key == "type":
+				typeOverride = FieldType(strings.ToLower(value))
+				if !isKnownFieldType(typeOverride) {
+					return nil, fmt.Errorf("%s.%s: unknown type %q", stmt.Schema.Name, f.Name, value)
+				}
+			default:
+				return nil, fmt.Errorf("%s.%s: unknown %s tag item %q", stmt.Schema.Name, f.Name, TagName, item)
+			}
+		}
+		if search && !filter {
+			// Quick search only matches allowlisted fields; without filter the flag would do nothing.
+			return nil, fmt.Errorf("%s.%s: search requires filter", stmt.Schema.Name, f.Name)
+		}
+
+		values, isEnum := inferEnumValues(f.FieldType)
+		if isEnum && (typeOverride == "" || typeOverride == FieldTypeEnum) {
+			opts.WithEnumValues(column, values)
+		} else if typeOverride != "" {
+			opts.FieldTypes[column] = typeOverride
+		} else if ft, ok := inferSchemaFieldType(f); ok {
+			opts.FieldTypes[column] = ft
+		}
+	}
+
+	if len(opts.AllowedFields) == 0 {
+		return nil, fmt.Errorf("model %s has no fields tagged %q", stmt.Schema.Name, TagName+":\"filter\"")
+	}
+	return opts, nil
+}
+
+func addField(set map[string]struct{}, field string) map[string]struct{} {
+	if set == nil {
+		set = map[string]struct{}{}
+	}
+	set[field] = struct{}{}
+	return set
+}
@@ -0,0 +1,110 @@
+package go_dbsearch
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type taggedModel struct {
+	ID        uint   `dbsearch:"sort"`
+	Name      string `dbsearch:"filter,sort,search,select"`
+	Status    string `dbsearch:"filter,ops=eq|in,group"`
+	Salary    int    `dbsearch:"-"`
+	Secret    string
+	CreatedAt time.Time `dbsearch:"filter,sort,alias=createdAt,type=date"`
+}
+
+func openTagTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	return db
+}
+
+func TestOptionsFromModel(t *testing.T) {
+	opts, err := OptionsFromModel(openTagTestDB(t), &taggedModel{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	for _, f := range []string{"name", "status", "created_at"} {
+		if _, ok := opts.AllowedFields[f]; !ok {
+			t.Fatalf("%s should be filterable", f)
+		}
+	}
+	for _, f := range []string{"id", "salary", "secret"} {
+		if _, ok := opts.AllowedFields[f]; ok {
+			t.Fatalf("%s should not be filterable", f)
+		}
+	}
+	if _, ok := opts.SortableFields["status"]; ok {
+		t.Fatalf("status should not be sortable")
+	}
+	if opts.FieldTypes["created_at"] != FieldTypeDate || opts.FieldTypes["name"] != FieldTypeString {
+		t.Fatalf("unexpected field types: %v", opts.FieldTypes)
+	}
+	if opts.FieldAliases["createdAt"] != "created_at" {
+		t.Fatalf("unexpected aliases: %v", opts.FieldAliases)
+	}
+	if len(opts.QuickSearchFields) != 1 || opts.QuickSearchFields[0] != "name" {
+		t.Fatalf("unexpected quick search fields: %v", opts.QuickSearchFields)
+	}
+
+	values := url.Values{}
+	values.Set("filter[createdAt:gte]", "2024-01-01")
+	values.Set("filter[status:like]", "act")
+	values.Set("filter[status:in]", "a,b")
+	values.Set("sort", "-createdAt,status,id")
+	q, err := ParseQueryWithOptions(values, opts)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(q.Filters) != 2 {
+		t.Fatalf("expected createdAt and status IN filters, got %+v", q.Filters)
+	}
+	for _, f := range q.Filters {
+		if f.Field == "created_at" {
+			if _, ok := f.Value.(time.Time); !ok {
+				t.Fatalf("created_at should be cast as a date, got %T", f.Value)
+			}
+		} else if f.Field != "status" || f.Op != "IN" {
+			t.Fatalf("unexpected filter: %+v", f)
+		}
+	}
+	if len(q.Sorts) != 2 || q.Sorts[0].Field != "created_at" || q.Sorts[1].Field != "id" {
+		t.Fatalf("unexpected sorts: %+v", q.Sorts)
+	}
+}
+
+func TestOptionsFromModel_Errors(t *testing.T) {
+	type typo struct {
+		Name string `dbsearch:"filtr"`
+	}
+	type badOp struct {
+		Name string `dbsearch:"filter,ops=eq|regex"`
+	}
+	type untagged struct {
+		Name string
+	}
+	type searchOnly struct {
+		Name string `dbsearch:"search"`
+	}
+	type aliasColumn struct {
+		Name  string `dbsearch:"filter"`
+		Title string `dbsearch:"filter,alias=name"`
+	}
+	type aliasTwice struct {
+		Name  string `dbsearch:"filter,alias=label"`
+		Title string `dbsearch:"filter,alias=label"`
+	}
+	for _, model := range []any{&typo{}, &badOp{}, &untagged{}, &searchOnly{}, &aliasColumn{}, &aliasTwice{}} {
+		if _, err := OptionsFromModel(openTagTestDB(t), model); err == nil {
+			t.Fatalf("%T: expected error", model)
+		}
+	}
+}
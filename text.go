@@ -0,0 +1,116 @@
+package go_dbsearch
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextForm is a Unicode normalization form.
+type TextForm string
+
+const (
+	// TextFormNFC composes characters canonically ("e" + U+0301 → "é").
+	TextFormNFC TextForm = "nfc"
+	// TextFormNFKC also folds compatibility variants (full-width letters, ligatures, Arabic
+	// presentation forms).
+	TextFormNFKC TextForm = "nfkc"
+)
+
+// TextNormalization configures how a string field's filter and quick search values are normalized
+// (Options.TextNormalization). Steps run in field order below.
+//
+// Normalizing values only helps if the stored text is normalized the same way. Either store
+// normalized text, or keep the original column and fill a shadow Column with Apply on write; the
+// library then matches against Column instead of the field's own column.
+type TextNormalization struct {
+	// Form is the Unicode normalization form ("" leaves composition unchanged).
+	Form TextForm `json:"form,omitempty" yaml:"form,omitempty"`
+	// FoldAccents removes combining marks ("Café" → "Cafe", Arabic diacritics).
+	FoldAccents bool `json:"fold_accents,omitempty" yaml:"fold_accents,omitempty"`
+	// UnifyArabic maps Arabic Yeh/Alef Maksura to Farsi Yeh, Arabic Kaf to Keheh, hamza-carrying
+	// Alefs to Alef and Arabic-Indic/Persian digits to ASCII, and removes Tatweel.
+	UnifyArabic bool `json:"unify_arabic,omitempty" yaml:"unify_arabic,omitempty"`
+	// FoldCase applies Unicode case folding.
+	FoldCase bool `json:"fold_case,omitempty" yaml:"fold_case,omitempty"`
+	// CollapseSpace trims and collapses runs of whitespace to one space.
+	CollapseSpace bool `json:"collapse_space,omitempty" yaml:"collapse_space,omitempty"`
+
+	// Column, if set, is the shadow column holding the field's normalized text. Filters and quick
+	// search on the field target it; sorting, selection and results keep the original column.
+	Column string `json:"column,omitempty" yaml:"column,omitempty"`
+}
+
+// Apply normalizes s. Use it to fill a shadow Column so stored and queried text match.
+func (n TextNormalization) Apply(s string) string {
+	var steps []transform.Transformer
+	decompose, compose := norm.NFD, norm.NFC
+	if n.Form == TextFormNFKC {
+		decompose, compose = norm.NFKD, norm.NFKC
+	}
+	switch {
+	case n.FoldAccents:
+		steps = append(steps, decompose, runes.Remove(runes.In(unicode.Mn)), compose)
+	case n.Form != "":
+		steps = append(steps, compose)
+	}
+	if n.UnifyArabic {
+		steps = append(steps, runes.Remove(runes.Predicate(isTatweel)), runes.Map(unifyArabicRune))
+	}
+	if n.FoldCase {
+		steps = append(steps, cases.Fold())
+	}
+	if len(steps) > 0 {
+		if out, _, err := transform.String(transform.Chain(steps...), s); err == nil {
+			s = out
+		}
+	}
+	if n.CollapseSpace {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	return s
+}
+
+func (n TextNormalization) validate(field string) error {
+	switch n.Form {
+	case "", TextFormNFC, TextFormNFKC:
+	default:
+		return fmt.Errorf("TextNormalization[%q]: unknown form %q", field, n.Form)
+	}
+	if n.Column != "" && !safeFieldRe.MatchString(n.Column) {
+		return fmt.Errorf("TextNormalization[%q]: invalid column %q", field, n.Column)
+	}
+	return nil
+}
+
+func isTatweel(r rune) bool { return r == 'ـ' }
+
+func unifyArabicRune(r rune) rune {
+	switch {
+	case r == 'ي' || r == 'ى': // Arabic Yeh, Alef Maksura
+		return 'ی' // Farsi Yeh
+	case r == 'ك': // Arabic Kaf
+		return 'ک' // Keheh
+	case r == 'أ' || r == 'إ' || r == 'ٱ': // Alef with Hamza above/below, Alef Wasla
+		return 'ا'
+	case r >= '٠' && r <= '٩': // Arabic-Indic digits
+		return '0' + (r - '٠')
+	case r >= '۰' && r <= '۹': // Extended Arabic-Indic (Persian) digits
+		return '0' + (r - '۰')
+	default:
+		return r
+	}
+}
+
+// normalizeText applies the field's TextNormalization (keyed by field or shadow column) to s.
+func (c *ValueCaster) normalizeText(field, s string) string {
+	if n, ok := c.text[field]; ok {
+		return n.Apply(s)
+	}
+	return s
+}
@@ -0,0 +1,104 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTextNormalization_Apply(t *testing.T) {
+	cases := []struct {
+		n        TextNormalization
+		in, want string
+	}{
+		{TextNormalization{FoldAccents: true, FoldCase: true, CollapseSpace: true}, "  Café \t au   Lait ", "cafe au lait"},
+		{TextNormalization{Form: TextFormNFC}, "Café", "Café"},
+		{TextNormalization{Form: TextFormNFKC}, "ＡＢＣ ﬁ", "ABC fi"},
+		{TextNormalization{UnifyArabic: true}, "علي كريم", "علی کریم"},
+		{TextNormalization{UnifyArabic: true}, "كـتاب ۱۲۳ ٤٥", "کتاب 123 45"},
+		{TextNormalization{UnifyArabic: true, FoldAccents: true}, "أَحمد", "احمد"},
+		{TextNormalization{}, " As Is ", " As Is "},
+	}
+	for _, c := range cases {
+		if got := c.n.Apply(c.in); got != c.want {
+			t.Fatalf("%+v %q: got %q, want %q", c.n, c.in, got, c.want)
+		}
+	}
+}
+
+func TestTextNormalization_Validation(t *testing.T) {
+	for _, opts := range []*Options{
+		NewOptions([]string{"age"}).WithFieldTypes(map[string]FieldType{"age": FieldTypeInt}).
+			WithTextNormalization("age", TextNormalization{FoldCase: true}),
+		NewOptions([]string{"name"}).WithTextNormalization("name", TextNormalization{Form: "nfd"}),
+		NewOptions([]string{"name"}).WithTextNormalization("name", TextNormalization{Column: "name; drop"}),
+	} {
+		if _, err := NewValidatorFromOptions(opts); err == nil {
+			t.Fatalf("expected error for %+v", opts.TextNormalization)
+		}
+	}
+}
+
+type textRow struct {
+	ID       int
+	Name     string
+	NameNorm string
+}
+
+func TestTextNormalization_ShadowColumn(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&textRow{}); err != nil {
+		t.Fatal(err)
+	}
+	n := TextNormalization{FoldAccents: true, UnifyArabic: true, FoldCase: true, CollapseSpace: true, Column: "name_norm"}
+	for i, name := range []string{"Café  Crème", "علي كريم", "Tea"} {
+		db.Create(&textRow{ID: i + 1, Name: name, NameNorm: n.Apply(name)})
+	}
+
+	opts := NewOptions([]string{"name"}).
+		WithQuickSearchFields("name").
+		WithTextNormalization("name", n)
+
+	for query, want := range map[string]string{
+		"filter[name:like]=CAFE":       "Café  Crème",
+		"filter[name]=cafe creme":      "Café  Crème",
+		"filter[name:in]=tea,x":        "Tea",
+		"filter[name:like]=علی":        "علي كريم",
+		"q=" + url.QueryEscape("كريم"): "علي كريم",
+	} {
+		values, _ := url.ParseQuery(query)
+		q, err := ParseQueryWithOptions(values, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		var rows []textRow
+		if err := ApplyWithOptions(db.Model(&textRow{}), q, opts).Find(&rows).Error; err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		if len(rows) != 1 || rows[0].Name != want {
+			t.Fatalf("%s: got %+v, want %q", query, rows, want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	body := `{"filters": {"and": [{"filter": {"field": "name", "op": "like", "value": "crème"}}]}}`
+	NewAdvancedSearchHandler[textRow](db, textRow{}, opts).ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(body)))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Café  Crème") || strings.Contains(w.Body.String(), "Tea") {
+		t.Fatalf("advanced search: got %d %s", w.Code, w.Body.String())
+	}
+
+	// The shadow column is not exposed for filtering directly.
+	values, _ := url.ParseQuery("filter[name_norm]=tea")
+	if q, _ := ParseQueryWithOptions(values, opts); len(q.Filters) != 0 {
+		t.Fatalf("shadow column should not be filterable: %+v", q.Filters)
+	}
+}
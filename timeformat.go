@@ -0,0 +1,163 @@
+package go_dbsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Special Options.TimeLayouts entries for Unix timestamps (integer strings or JSON numbers).
+const (
+	// LayoutEpoch accepts seconds or milliseconds since the Unix epoch, told apart by magnitude:
+	// values of 1e11 and above (after 1973-03-03 as milliseconds, year 5138 as seconds) are
+	// milliseconds.
+	LayoutEpoch = "epoch"
+	// LayoutEpochSeconds accepts seconds since the Unix epoch.
+	LayoutEpochSeconds = "epoch_s"
+	// LayoutEpochMillis accepts milliseconds since the Unix epoch.
+	LayoutEpochMillis = "epoch_ms"
+)
+
+var (
+	defaultDateLayouts = []string{"2006-01-02", LayoutEpoch}
+	defaultTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", LayoutEpoch}
+
+	isoMonthRe = regexp.MustCompile(`^\d{4}-\d{2}$`)
+	isoWeekRe  = regexp.MustCompile(`^(\d{4})-?[Ww](\d{2})$`)
+	epochRe    = regexp.MustCompile(`^-?\d+$`)
+)
+
+// castTime casts a FieldTypeDate, FieldTypeCalendarDate or FieldTypeTime value. Period shorthands
+// ("2024-03" for a month, "2024-W11" for an ISO week) become a Range; dates are truncated to local
+// midnight, calendar dates to the day itself (see calendarDay).
+//
+// Results are in UTC: drivers such as go-sqlite3 bind a time.Time with its own offset, and text
+// comparison against UTC-stored timestamps would otherwise be wrong for request time zones.
+func (c *ValueCaster) castTime(field string, t FieldType, raw string) (interface{}, error) {
+	s := strings.TrimSpace(raw)
+	kind := "time"
+	if t != FieldTypeTime {
+		kind = "date"
+	}
+
+	if r, ok := c.parsePeriod(s); ok {
+		if t == FieldTypeCalendarDate {
+			return Range{From: calendarDay(r.From.(time.Time), c.loc), To: calendarDay(r.To.(time.Time), c.loc)}, nil
+		}
+		return Range{From: r.From.(time.Time).UTC(), To: r.To.(time.Time).UTC()}, nil
+	}
+	tt, err := c.parseTime(field, t, s)
+	if err != nil {
+		if isRelativeTime(s) {
+			rel, relErr := evalRelativeTime(s, c.now().In(c.loc))
+			if relErr != nil {
+				return nil, fmt.Errorf("invalid %s for %s: %w", kind, field, relErr)
+			}
+			tt = rel
+		} else {
+			return nil, fmt.Errorf("invalid %s for %s: %q", kind, field, raw)
+		}
+	}
+	switch t {
+	case FieldTypeDate:
+		tt = tt.In(c.loc)
+		return time.Date(tt.Year(), tt.Month(), tt.Day(), 0, 0, 0, 0, c.loc).UTC(), nil
+	case FieldTypeCalendarDate:
+		return calendarDay(tt, c.loc), nil
+	}
+	return tt.UTC(), nil
+}
+
+// calendarDay returns the day t falls on in loc as UTC midnight, the form DATE columns are bound
+// and stored in, so no zone shifts the day.
+func calendarDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// parseTime tries the field's layouts in order.
+func (c *ValueCaster) parseTime(field string, t FieldType, s string) (time.Time, error) {
+	layouts := c.layouts[field]
+	if len(layouts) == 0 {
+		layouts = defaultTimeLayouts
+		if t != FieldTypeTime {
+			layouts = defaultDateLayouts
+		}
+	}
+	for _, layout := range layouts {
+		switch layout {
+		case LayoutEpoch, LayoutEpochSeconds, LayoutEpochMillis:
+			if tt, ok := parseEpoch(s, layout); ok {
+				return tt.In(c.loc), nil
+			}
+		default:
+			if tt, err := time.ParseInLocation(layout, s, c.loc); err == nil {
+				return tt, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("no layout matches %q", s)
+}
+
+func parseEpoch(s, layout string) (time.Time, bool) {
+	if !epochRe.MatchString(s) {
+		return time.Time{}, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	millis := layout == LayoutEpochMillis || (layout == LayoutEpoch && (n >= 1e11 || n <= -1e11))
+	if millis {
+		return time.UnixMilli(n), true
+	}
+	return time.Unix(n, 0), true
+}
+
+// parsePeriod parses ISO-8601 month ("2024-03") and week ("2024-W11", "2024W11") shorthands into
+// a Range of local midnights.
+func (c *ValueCaster) parsePeriod(s string) (Range, bool) {
+	if isoMonthRe.MatchString(s) {
+		start, err := time.ParseInLocation("2006-01", s, c.loc)
+		if err != nil {
+			return Range{}, false
+		}
+		return Range{From: start, To: start.AddDate(0, 1, 0)}, true
+	}
+	if m := isoWeekRe.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		// Week 1 is the week containing January 4th; weeks start on Monday.
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, c.loc)
+		start := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+7*(week-1))
+		if y, w := start.ISOWeek(); week < 1 || y != year || w != week {
+			return Range{}, false
+		}
+		return Range{From: start, To: start.AddDate(0, 0, 7)}, true
+	}
+	return Range{}, false
+}
+
+// castEpochJSON casts a JSON number for a date or time field as a Unix timestamp using the field's
+// layouts.
+func (c *ValueCaster) castEpochJSON(field string, v interface{}) (interface{}, error) {
+	var s string
+	switch vv := v.(type) {
+	case json.Number:
+		s = vv.String()
+	case float64:
+		if vv != math.Trunc(vv) || math.Abs(vv) > 1<<62 {
+			return nil, fmt.Errorf("invalid epoch value for %s: %v", field, vv)
+		}
+		s = strconv.FormatInt(int64(vv), 10)
+	case int:
+		s = strconv.Itoa(vv)
+	case int64:
+		s = strconv.FormatInt(vv, 10)
+	}
+	return c.CastFromString(field, s)
+}
@@ -0,0 +1,163 @@
+package go_dbsearch
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestValueCaster_TimeFormats(t *testing.T) {
+	c := NewValueCaster(NewOptions([]string{"at", "day"}).
+		WithFieldTypes(map[string]FieldType{"at": FieldTypeTime, "day": FieldTypeDate}))
+
+	want := time.Date(2024, 3, 10, 8, 30, 0, 123000000, time.UTC)
+	for _, raw := range []string{
+		"2024-03-10T08:30:00.123Z",
+		"2024-03-10T10:30:00.123+02:00",
+		"2024-03-10 08:30:00.123",
+		"1710059400123",
+	} {
+		v, err := c.CastFromString("at", raw)
+		if err != nil {
+			t.Fatalf("%s: %v", raw, err)
+		}
+		if !v.(time.Time).Equal(want) {
+			t.Fatalf("%s: got %v, want %v", raw, v, want)
+		}
+	}
+
+	v, err := c.CastFromString("at", "1710059400")
+	if err != nil || !v.(time.Time).Equal(want.Truncate(time.Second)) {
+		t.Fatalf("epoch seconds: got %v, %v", v, err)
+	}
+	v, err = c.NormalizeJSONValue("day", json.Number("1710059400123"))
+	if err != nil || !v.(time.Time).Equal(time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("epoch millis date: got %v, %v", v, err)
+	}
+	if _, err := c.NormalizeJSONValue("at", 1.5); err == nil {
+		t.Fatal("expected error for fractional epoch")
+	}
+}
+
+func TestValueCaster_TimeLayouts(t *testing.T) {
+	opts := NewOptions([]string{"day", "at"}).
+		WithFieldTypes(map[string]FieldType{"day": FieldTypeDate, "at": FieldTypeTime}).
+		WithTimeLayouts("day", "02/01/2006").
+		WithTimeLayouts("at", LayoutEpochSeconds)
+	if _, err := NewValidatorFromOptions(opts); err != nil {
+		t.Fatal(err)
+	}
+	c := NewValueCaster(opts)
+
+	v, err := c.CastFromString("day", "10/03/2024")
+	if err != nil || !v.(time.Time).Equal(time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("custom layout: got %v, %v", v, err)
+	}
+	if _, err := c.CastFromString("day", "2024-03-10"); err == nil {
+		t.Fatal("default layout should be replaced")
+	}
+	v, err = c.CastFromString("at", "1710059400123")
+	if err != nil || v.(time.Time).Year() < 50000 {
+		t.Fatalf("epoch_s should not guess millis: got %v, %v", v, err)
+	}
+	// Relative expressions still work.
+	if _, err := c.CastFromString("day", "today"); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.WithTimeLayouts("name", "2006")
+	opts.AllowedFields["name"] = struct{}{}
+	if _, err := NewValidatorFromOptions(opts); err == nil {
+		t.Fatal("expected error for layouts on a non-time field")
+	}
+}
+
+func TestValueCaster_Periods(t *testing.T) {
+	c := NewValueCaster(NewOptions([]string{"day"}).WithFieldTypes(map[string]FieldType{"day": FieldTypeDate}))
+	cases := map[string]Range{
+		"2024-02":  {From: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		"2024-W11": {From: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+		"2021W01":  {From: time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 1, 11, 0, 0, 0, 0, time.UTC)},
+		"2020-W53": {From: time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	for raw, want := range cases {
+		v, err := c.CastFromString("day", raw)
+		if err != nil {
+			t.Fatalf("%s: %v", raw, err)
+		}
+		r, ok := v.(Range)
+		if !ok || !r.From.(time.Time).Equal(want.From.(time.Time)) || !r.To.(time.Time).Equal(want.To.(time.Time)) {
+			t.Fatalf("%s: got %#v", raw, v)
+		}
+	}
+	for _, raw := range []string{"2021-W53", "2024-W00", "2024-13"} {
+		if _, err := c.CastFromString("day", raw); err == nil {
+			t.Fatalf("%s: expected error", raw)
+		}
+	}
+
+	v := c.betweenValue("day", cases["2024-02"], cases["2024-W11"]).(Range)
+	if !v.From.(time.Time).Equal(cases["2024-02"].From.(time.Time)) || !v.To.(time.Time).Equal(cases["2024-W11"].To.(time.Time)) {
+		t.Fatalf("between periods: got %#v", v)
+	}
+}
+
+type eventRow struct {
+	ID int
+	At time.Time
+}
+
+func TestPeriodFilters_SQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&eventRow{}); err != nil {
+		t.Fatal(err)
+	}
+	for i, at := range []time.Time{
+		time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		db.Create(&eventRow{ID: i + 1, At: at})
+	}
+
+	opts := NewOptions([]string{"at"}).WithFieldTypes(map[string]FieldType{"at": FieldTypeTime})
+	for filter, want := range map[string][]int{
+		"filter[at:eq]=2024-03":              {2, 3},
+		"filter[at:gte]=2024-03":             {2, 3, 4},
+		"filter[at:gt]=2024-03":              {4},
+		"filter[at:lt]=2024-03":              {1},
+		"filter[at:lte]=2024-03":             {1, 2, 3},
+		"filter[at:in]=2024-02,2024-04":      {1, 4},
+		"filter[at:between]=2024-02,2024-03": {1, 2, 3},
+	} {
+		values, _ := url.ParseQuery(filter)
+		q, err := ParseQueryWithOptions(values, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", filter, err)
+		}
+		var rows []eventRow
+		if err := ApplyWithOptions(db.Model(&eventRow{}), q, opts).Order("id").Find(&rows).Error; err != nil {
+			t.Fatalf("%s: %v", filter, err)
+		}
+		var got []int
+		for _, r := range rows {
+			got = append(got, r.ID)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: got ids %v, want %v", filter, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("%s: got ids %v, want %v", filter, got, want)
+			}
+		}
+	}
+}
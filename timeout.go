@@ -0,0 +1,90 @@
+package go_dbsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StatusClientClosedRequest is the non-standard status (popularized by nginx) returned when the
+// client went away before the query finished.
+const StatusClientClosedRequest = 499
+
+var (
+	// ErrQueryTimeout reports a query stopped by Options.StatementTimeout or a context deadline.
+	ErrQueryTimeout = errors.New("search query timed out")
+	// ErrQueryCanceled reports a query stopped because the request context was canceled.
+	ErrQueryCanceled = errors.New("search query canceled")
+)
+
+// queryContext derives the context for a request's queries, applying timeout when > 0.
+func queryContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// runQuery runs fn against db bound to ctx (see queryContext).
+//
+// On postgres a positive timeout is also set server-side with SET LOCAL statement_timeout inside a
+// transaction, so the database stops the statement even if the driver cannot cancel it. Other
+// dialects rely on the context deadline. Errors caused by the deadline or cancellation are
+// wrapped in ErrQueryTimeout/ErrQueryCanceled.
+func runQuery(ctx context.Context, db *gorm.DB, timeout time.Duration, fn func(tx *gorm.DB) error) error {
+	tx := db.WithContext(ctx)
+
+	var err error
+	if timeout > 0 && dialectName(tx) == "postgres" {
+		err = tx.Transaction(func(tx *gorm.DB) error {
+			// Milliseconds are formatted from an integer; SET does not accept bind parameters.
+			ms := timeout.Milliseconds()
+			if ms < 1 {
+				ms = 1
+			}
+			if err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", ms)).Error; err != nil {
+				return err
+			}
+			return fn(tx)
+		})
+	} else {
+		err = fn(tx)
+	}
+	return queryError(ctx, err)
+}
+
+// queryError classifies err using the state of ctx and the database error text.
+func queryError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case errors.Is(err, ErrQueryTimeout), errors.Is(err, ErrQueryCanceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(ctx.Err(), context.DeadlineExceeded),
+		strings.Contains(err.Error(), "statement timeout"):
+		return fmt.Errorf("%w: %v", ErrQueryTimeout, err)
+	case errors.Is(err, context.Canceled), errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("%w: %v", ErrQueryCanceled, err)
+	default:
+		return err
+	}
+}
+
+// queryStatus maps a query execution error to an HTTP status:
+// 504 for timeouts, 499 for canceled requests, 500 otherwise.
+func queryStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrQueryTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrQueryCanceled):
+		return StatusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,96 @@
+package go_dbsearch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandlers_ClientCanceled(t *testing.T) {
+	db := setupTestDB(t)
+	opts := NewOptions([]string{"name"})
+	router := gin.New()
+	router.GET("/test", SearchHandlerWithOptions[TestModel](db, TestModel{}, opts))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/test", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != StatusClientClosedRequest {
+		t.Fatalf("gin: expected 499, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/test", bytes.NewBufferString(`{}`)).WithContext(ctx)
+	NewAdvancedSearchHandler[TestModel](db, TestModel{}, opts).ServeHTTP(w, req)
+	if w.Code != StatusClientClosedRequest {
+		t.Fatalf("net/http: expected 499, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandlers_StatementTimeout(t *testing.T) {
+	db := setupTestDB(t)
+	opts := NewOptions([]string{"name"}).WithGroupableFields("name").WithStatementTimeout(time.Nanosecond)
+
+	for name, h := range map[string]HandlerFunc{
+		"search":    NewSearchHandler[TestModel](db, TestModel{}, opts),
+		"aggregate": NewAggregateHandler[TestModel](db, TestModel{}, opts),
+	} {
+		w := httptest.NewRecorder()
+		method := "GET"
+		if name == "aggregate" {
+			method = "POST"
+		}
+		h.ServeHTTP(w, httptest.NewRequest(method, "/test", bytes.NewBufferString(`{"group_by": ["name"]}`)))
+		if w.Code != http.StatusGatewayTimeout {
+			t.Fatalf("%s: expected 504, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	opts.StatementTimeout = time.Minute
+	w := httptest.NewRecorder()
+	NewSearchHandler[TestModel](db, TestModel{}, opts).ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 within the timeout, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestQueryError_Classification(t *testing.T) {
+	ctx := context.Background()
+	if err := queryError(ctx, errors.New("ERROR: canceling statement due to statement timeout (SQLSTATE 57014)")); queryStatus(err) != http.StatusGatewayTimeout {
+		t.Fatalf("expected postgres statement timeout to map to 504, got %v", err)
+	}
+	if err := queryError(ctx, context.Canceled); queryStatus(err) != StatusClientClosedRequest {
+		t.Fatalf("expected 499, got %v", err)
+	}
+	if err := queryError(ctx, errors.New("no such table")); queryStatus(err) != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %v", err)
+	}
+}
+
+func TestOptionsConfig_StatementTimeout(t *testing.T) {
+	cfg, err := ParseOptionsJSON([]byte(`{"allowed_fields": ["name"], "statement_timeout": "250ms"}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	opts, err := cfg.Build(openTagTestDB(t), &inferModel{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if opts.StatementTimeout != 250*time.Millisecond {
+		t.Fatalf("expected 250ms, got %v", opts.StatementTimeout)
+	}
+
+	cfg.StatementTimeout = "soon"
+	if _, err := cfg.Build(openTagTestDB(t), &inferModel{}); err == nil {
+		t.Fatal("expected invalid duration error")
+	}
+}
@@ -0,0 +1,31 @@
+package go_dbsearch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// TimezoneParam is the query parameter read when Options.RequestTimezone is set.
+	TimezoneParam = "tz"
+	// TimezoneHeader is the request header read when Options.RequestTimezone is set
+	// (the query parameter wins when both are present).
+	TimezoneHeader = "X-Timezone"
+)
+
+// withTimezone returns a snapshot casting dates in the named IANA zone. It returns s unchanged
+// when name is empty or Options.RequestTimezone is off.
+func (s *Snapshot) withTimezone(name string) (*Snapshot, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || s.opts == nil || !s.opts.RequestTimezone || s.caster == nil {
+		return s, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("invalid timezone: %q", name)
+	}
+	out := *s
+	out.caster = s.caster.inLocation(loc)
+	return &out, nil
+}
@@ -0,0 +1,208 @@
+package go_dbsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	return loc
+}
+
+func TestValueCaster_Location(t *testing.T) {
+	tehran := mustLoadLocation(t, "Asia/Tehran")
+	opts := NewOptions([]string{"day", "at"}).
+		WithFieldTypes(map[string]FieldType{"day": FieldTypeDate, "at": FieldTypeTime}).
+		WithLocation(tehran)
+	c := NewValueCaster(opts)
+
+	v, err := c.CastFromString("day", "2024-03-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 10, 0, 0, 0, 0, tehran); !v.(time.Time).Equal(want) {
+		t.Fatalf("date: got %v, want %v", v, want)
+	}
+
+	v, err = c.CastFromString("at", "2024-03-10 08:30:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC); !v.(time.Time).Equal(want) {
+		t.Fatalf("zone-less time: got %v, want %v", v, want)
+	}
+
+	// An explicit offset wins over the location.
+	v, err = c.CastFromString("at", "2024-03-10T08:30:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 10, 8, 30, 0, 0, time.UTC); !v.(time.Time).Equal(want) {
+		t.Fatalf("RFC3339 time: got %v, want %v", v, want)
+	}
+}
+
+func TestParseQuery_DateBetweenCoversLocalDays(t *testing.T) {
+	tehran := mustLoadLocation(t, "Asia/Tehran")
+	opts := NewOptions([]string{"day"}).
+		WithFieldTypes(map[string]FieldType{"day": FieldTypeDate}).
+		WithRequestTimezone(true)
+
+	values := url.Values{}
+	values.Set("filter[day:between]", "2024-03-01,2024-03-31")
+	values.Set(TimezoneParam, "Asia/Tehran")
+	q, err := ParseQueryWithContext(context.Background(), values, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, ok := q.Filters[0].Value.(Range)
+	if !ok {
+		t.Fatalf("expected Range, got %T", q.Filters[0].Value)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, tehran); !r.From.(time.Time).Equal(want) {
+		t.Fatalf("from: got %v, want %v", r.From, want)
+	}
+	if want := time.Date(2024, 4, 1, 0, 0, 0, 0, tehran); !r.To.(time.Time).Equal(want) {
+		t.Fatalf("to: got %v, want %v", r.To, want)
+	}
+
+	values.Set(TimezoneParam, "Mars/Olympus")
+	if _, err := ParseQueryWithContext(context.Background(), values, opts); err == nil {
+		t.Fatal("expected error for unknown time zone")
+	}
+
+	// Without RequestTimezone the parameter is ignored.
+	opts.RequestTimezone = false
+	if _, err := ParseQueryWithContext(context.Background(), values, opts); err != nil {
+		t.Fatalf("tz should be ignored: %v", err)
+	}
+}
+
+func TestRangeFilter_SQL(t *testing.T) {
+	db := setupTestDB(t)
+	q := SearchQuery{Filters: []Filter{{Field: "age", Op: "BETWEEN", Value: Range{From: 20, To: 30}}}}
+	exp, err := Explain(ApplyWithOptions(db.Model(&TestModel{}), q, NewOptions([]string{"age"})), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "age >= ? AND age < ?"; !strings.Contains(exp.SQL, want) {
+		t.Fatalf("expected %q in %s", want, exp.SQL)
+	}
+}
+
+func TestHandlers_RequestTimezone(t *testing.T) {
+	mustLoadLocation(t, "Europe/Berlin")
+	db := setupTestDB(t)
+	opts := NewOptions([]string{"name"}).WithRequestTimezone(true)
+	h := NewSearchHandler[TestModel](db, TestModel{}, opts)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(TimezoneHeader, "Europe/Berlin")
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/test?tz=Nowhere/Special", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+type tzEvent struct {
+	ID   int
+	Name string
+	At   time.Time
+}
+
+func TestHandlers_RequestTimezoneAgainstDB(t *testing.T) {
+	mustLoadLocation(t, "Asia/Tehran")
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&tzEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	// 01:00 on 2024-03-10 in Tehran.
+	db.Create(&tzEvent{Name: "early", At: time.Date(2024, 3, 9, 21, 30, 0, 0, time.UTC)})
+	db.Create(&tzEvent{Name: "before", At: time.Date(2024, 3, 9, 20, 0, 0, 0, time.UTC)})
+	opts := NewOptions([]string{"at"}).
+		WithFieldTypes(map[string]FieldType{"at": FieldTypeDate}).
+		WithRequestTimezone(true)
+	h := NewSearchHandler[tzEvent](db, tzEvent{}, opts)
+
+	for _, query := range []string{
+		"filter[at:between]=2024-03-10,2024-03-10",
+		"filter[at:gte]=2024-03-10",
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/events?tz=Asia/Tehran&"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+		}
+		if body := w.Body.String(); !strings.Contains(body, `"early"`) || strings.Contains(body, `"before"`) {
+			t.Fatalf("%s: expected only the early event, got %s", query, body)
+		}
+	}
+}
+
+type tzHoliday struct {
+	ID   int
+	Name string
+	Day  time.Time `gorm:"type:date"`
+}
+
+func TestHandlers_CalendarDateIgnoresZone(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&tzHoliday{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&tzHoliday{Name: "eve", Day: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)})
+	db.Create(&tzHoliday{Name: "newyear", Day: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	opts := NewOptions([]string{"day"}).WithLocation(newYork)
+	if err := InferFieldTypesFromModel(db, &tzHoliday{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.FieldTypes["day"] != FieldTypeCalendarDate {
+		t.Fatalf("expected a calendar date, got %q", opts.FieldTypes["day"])
+	}
+	h := NewSearchHandler[tzHoliday](db, tzHoliday{}, opts)
+
+	for _, query := range []string{
+		"filter[day]=2024-01-01",
+		"filter[day:between]=2024-01-01,2024-01-01",
+		"filter[day]=2024-01",
+		"filter[day:gt]=2023-12-31",
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/holidays?"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+		}
+		if body := w.Body.String(); !strings.Contains(body, `"newyear"`) || strings.Contains(body, `"eve"`) {
+			t.Fatalf("%s: expected only the new year, got %s", query, body)
+		}
+	}
+
+	// Relative expressions pick the day in the location: 03:00 UTC is still Dec 31 in New York.
+	c := NewValueCaster(opts.WithClock(func() time.Time { return time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC) }))
+	v, err := c.CastFromString("day", "today")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC); !v.(time.Time).Equal(want) {
+		t.Fatalf("today: got %v, want %v", v, want)
+	}
+}
@@ -3,10 +3,20 @@ package go_dbsearch
 import (
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"strings"
 )
 
+var (
+	// ErrFieldNotAllowed reports a field that is not in the allowlist.
+	ErrFieldNotAllowed = errors.New("field is not allowed")
+	// ErrFieldDenied reports an allowlisted field that the caller's Permissions do not grant.
+	ErrFieldDenied = errors.New("field is denied")
+	// ErrOperatorDenied reports a supported operator that the caller's Permissions do not grant.
+	ErrOperatorDenied = errors.New("operator is denied")
+)
+
 // safeFieldRe is a defense-in-depth check to prevent SQL fragments from being injected as an identifier.
 // It permits identifiers like: "name", "users.email", "created_at".
 var safeFieldRe = regexp.MustCompile(`^[a-zA-Z0-9_.]+$`)
@@ -14,6 +24,26 @@ var safeFieldRe = regexp.MustCompile(`^[a-zA-Z0-9_.]+$`)
 // Validator validates fields, operators and sorts against an allowlist.
 type Validator struct {
 	allowed map[string]struct{}
+
+	// sortable overrides allowed for sorts when non-nil.
+	sortable map[string]struct{}
+	// ops restricts canonical operators per field; fields without an entry accept all operators.
+	ops map[string]map[string]struct{}
+	// aliases maps external field names to columns.
+	aliases map[string]string
+
+	// denied holds allowlisted fields withheld by Permissions (reported as ErrFieldDenied).
+	denied map[string]struct{}
+	// deniedIncludes holds allowlisted includes withheld by Permissions.Includes.
+	deniedIncludes map[string]struct{}
+	// permittedOps, if non-nil, restricts canonical operators for every field (Permissions.Operators).
+	permittedOps map[string]struct{}
+	// scoped holds fields constrained by Options.Scopes; client filters on them are denied.
+	scoped map[string]struct{}
+	// shadow maps fields to their TextNormalization.Column; filters target the shadow column.
+	shadow map[string]string
+	// geo holds Options.GeoFields; geo fields accept only the near and bbox operators.
+	geo map[string]GeoField
 }
 
 // NewValidator creates a validator from a set of allowed fields.
@@ -35,11 +65,145 @@ func NewValidatorFromOptions(opts *Options) (*Validator, error) {
 	if opts.AllowedFields == nil || len(opts.AllowedFields) == 0 {
 		return nil, errors.New("AllowedFields is required (phase-4): provide a non-empty allowlist")
 	}
-	return NewValidator(opts.AllowedFields), nil
+	return newValidatorFromOptions(opts)
+}
+
+func newValidatorFromOptions(opts *Options) (*Validator, error) {
+	v := NewValidator(opts.AllowedFields)
+	if opts.SortableFields != nil {
+		v.sortable = make(map[string]struct{}, len(opts.SortableFields))
+		for k := range opts.SortableFields {
+			v.sortable[k] = struct{}{}
+		}
+	}
+	if len(opts.FieldOperators) > 0 {
+		v.ops = make(map[string]map[string]struct{}, len(opts.FieldOperators))
+		for field, ops := range opts.FieldOperators {
+			set := make(map[string]struct{}, len(ops))
+			for _, op := range ops {
+				n, err := ValidateOperator(op)
+				if err != nil {
+					return nil, fmt.Errorf("FieldOperators[%q]: %w", field, err)
+				}
+				set[n] = struct{}{}
+			}
+			v.ops[field] = set
+		}
+	}
+	for field, ft := range opts.FieldTypes {
+		if ft == "" || isPrimitiveFieldType(ft) {
+			continue
+		}
+		spec, ok := LookupFieldType(ft)
+		if !ok {
+			return nil, fmt.Errorf("FieldTypes[%q]: unknown type %q", field, ft)
+		}
+		if _, ok := v.ops[field]; ok || spec.Operators == nil {
+			continue
+		}
+		if v.ops == nil {
+			v.ops = map[string]map[string]struct{}{}
+		}
+		set := make(map[string]struct{}, len(spec.Operators))
+		for _, op := range spec.Operators {
+			n, _ := ValidateOperator(op)
+			set[n] = struct{}{}
+		}
+		v.ops[field] = set
+	}
+	for field, values := range opts.Enums {
+		if opts.FieldTypes[field] != FieldTypeEnum {
+			return nil, fmt.Errorf("Enums[%q]: field type must be %q", field, FieldTypeEnum)
+		}
+		if _, err := newEnumLookup(field, values); err != nil {
+			return nil, err
+		}
+	}
+	if opts.BoolValues != nil {
+		if _, err := newBoolLookup(*opts.BoolValues); err != nil {
+			return nil, err
+		}
+	}
+	for field, n := range opts.TextNormalization {
+		if t := opts.FieldTypes[field]; t != "" && t != FieldTypeString {
+			return nil, fmt.Errorf("TextNormalization[%q]: field type must be %q", field, FieldTypeString)
+		}
+		if err := n.validate(field); err != nil {
+			return nil, err
+		}
+		if n.Column != "" {
+			if v.shadow == nil {
+				v.shadow = map[string]string{}
+			}
+			v.shadow[field] = n.Column
+		}
+	}
+	for field, ft := range opts.FieldTypes {
+		if _, ok := opts.GeoFields[field]; ft == FieldTypeGeo && !ok {
+			return nil, fmt.Errorf("FieldTypes[%q]: geo fields must be declared in GeoFields", field)
+		}
+	}
+	if opts.MaxGeoRadius < 0 || math.IsNaN(opts.MaxGeoRadius) {
+		return nil, fmt.Errorf("MaxGeoRadius must be >= 0, got %v", opts.MaxGeoRadius)
+	}
+	for field, g := range opts.GeoFields {
+		if t := opts.FieldTypes[field]; t != "" && t != FieldTypeGeo {
+			return nil, fmt.Errorf("GeoFields[%q]: field type must be %q", field, FieldTypeGeo)
+		}
+		if err := g.validate(field); err != nil {
+			return nil, err
+		}
+		_, selectable := opts.SelectableFields[field]
+		_, groupable := opts.GroupableFields[field]
+		_, aggregatable := opts.AggregatableFields[field]
+		if selectable || groupable || aggregatable {
+			return nil, fmt.Errorf("GeoFields[%q]: geo fields cannot be selected, grouped or aggregated", field)
+		}
+		if v.geo == nil {
+			v.geo = map[string]GeoField{}
+		}
+		v.geo[field] = g
+	}
+	for field, layouts := range opts.TimeLayouts {
+		if t := opts.FieldTypes[field]; !isTimeFieldType(t) {
+			return nil, fmt.Errorf("TimeLayouts[%q]: field type must be %q, %q or %q", field, FieldTypeDate,
+				FieldTypeCalendarDate, FieldTypeTime)
+		}
+		if len(layouts) == 0 {
+			return nil, fmt.Errorf("TimeLayouts[%q]: no layouts", field)
+		}
+		for _, layout := range layouts {
+			if strings.TrimSpace(layout) == "" {
+				return nil, fmt.Errorf("TimeLayouts[%q]: empty layout", field)
+			}
+		}
+	}
+	if len(opts.FieldAliases) > 0 {
+		v.aliases = make(map[string]string, len(opts.FieldAliases))
+		for alias, column := range opts.FieldAliases {
+			v.aliases[alias] = column
+		}
+	}
+	return v, nil
 }
 
 // ValidateField validates that a field is safe to interpolate as a SQL identifier and is whitelisted.
 func (v *Validator) ValidateField(field string) error {
+	return v.checkField(v.allowed, field)
+}
+
+// checkField validates field against allowed, reporting fields withheld by Permissions distinctly.
+func (v *Validator) checkField(allowed map[string]struct{}, field string) error {
+	err := validateFieldIn(allowed, field)
+	if errors.Is(err, ErrFieldNotAllowed) {
+		if _, ok := v.denied[strings.TrimSpace(field)]; ok {
+			return fmt.Errorf("%w: %q", ErrFieldDenied, strings.TrimSpace(field))
+		}
+	}
+	return err
+}
+
+func validateFieldIn(allowed map[string]struct{}, field string) error {
 	field = strings.TrimSpace(field)
 	if field == "" {
 		return errors.New("field is empty")
@@ -47,12 +211,40 @@ func (v *Validator) ValidateField(field string) error {
 	if !safeFieldRe.MatchString(field) {
 		return fmt.Errorf("field contains invalid characters: %q", field)
 	}
-	if _, ok := v.allowed[field]; !ok {
-		return fmt.Errorf("field is not allowed: %q", field)
+	if _, ok := allowed[field]; !ok {
+		return fmt.Errorf("%w: %q", ErrFieldNotAllowed, field)
 	}
 	return nil
 }
 
+// ResolveField maps an external field name (alias) to its column and validates it.
+// Fields without an alias are validated as-is.
+func (v *Validator) ResolveField(field string) (string, error) {
+	field = strings.TrimSpace(field)
+	if column, ok := v.aliases[field]; ok {
+		field = column
+	}
+	if err := v.ValidateField(field); err != nil {
+		return "", err
+	}
+	return field, nil
+}
+
+// resolveSortField is ResolveField against the sortable allowlist.
+func (v *Validator) resolveSortField(field string) (string, error) {
+	if v.sortable == nil {
+		return v.ResolveField(field)
+	}
+	field = strings.TrimSpace(field)
+	if column, ok := v.aliases[field]; ok {
+		field = column
+	}
+	if err := v.checkField(v.sortable, field); err != nil {
+		return "", err
+	}
+	return field, nil
+}
+
 // NormalizeOperator converts operator aliases into canonical SQL operators.
 // Supported canonical ops: =, >, <, >=, <=, LIKE, IN, BETWEEN.
 // Supported aliases: eq, gt, lt, gte, lte, like, in, between (case-insensitive).
@@ -78,10 +270,14 @@ func NormalizeOperator(op string) (string, bool) {
 		return "IN", true
 	case "between":
 		return "BETWEEN", true
+	case "near":
+		return "NEAR", true
+	case "bbox":
+		return "BBOX", true
 	default:
 		s := strings.ToUpper(op)
 		switch s {
-		case "=", ">", "<", ">=", "<=", "LIKE", "IN", "BETWEEN":
+		case "=", ">", "<", ">=", "<=", "LIKE", "IN", "BETWEEN", "NEAR", "BBOX":
 			return s, true
 		default:
 			return "", false
@@ -108,31 +304,68 @@ func NormalizeSortDirection(direction string) (string, bool) {
 	return s, s == "ASC" || s == "DESC"
 }
 
-// ValidateSortOption validates and normalizes a sort option.
+// ValidateSortOption validates and normalizes a sort option (resolving aliases).
 func (v *Validator) ValidateSortOption(opt SortOption) (SortOption, error) {
-	if err := v.ValidateField(opt.Field); err != nil {
+	field, err := v.resolveSortField(opt.Field)
+	if err != nil {
 		return SortOption{}, err
 	}
+	opt.Field = field
 	dir, ok := NormalizeSortDirection(opt.Direction)
 	if !ok {
 		return SortOption{}, fmt.Errorf("invalid sort direction: %q", opt.Direction)
 	}
 	opt.Direction = dir
+	if _, ok := v.geo[field]; ok {
+		if opt.Near == nil {
+			return SortOption{}, fmt.Errorf("sorting by geo field %q requires a point", field)
+		}
+		if err := opt.Near.validate(); err != nil {
+			return SortOption{}, fmt.Errorf("sort %q: %w", field, err)
+		}
+		p := *opt.Near
+		opt.Near = &p
+	} else if opt.Near != nil {
+		return SortOption{}, fmt.Errorf("sort point given for non-geo field %q", field)
+	}
 	return opt, nil
 }
 
-// ValidateFilter validates a filter (field + operator) and normalizes Op in-place.
+// ValidateFilter validates a filter (field + operator) and normalizes Field (alias) and Op in-place.
+// Fields with a TextNormalization.Column are rewritten to that column.
 func (v *Validator) ValidateFilter(f *Filter) error {
 	if f == nil {
 		return nil
 	}
-	if err := v.ValidateField(f.Field); err != nil {
+	field, err := v.ResolveField(f.Field)
+	if err != nil {
 		return err
 	}
+	if _, ok := v.scoped[field]; ok {
+		return fmt.Errorf("%w: %q is scoped by the server", ErrFieldDenied, field)
+	}
 	op, err := ValidateOperator(f.Op)
 	if err != nil {
 		return err
 	}
+	if ops, ok := v.ops[field]; ok {
+		if _, ok := ops[op]; !ok {
+			return fmt.Errorf("operator %s is not allowed for field %q", op, field)
+		}
+	}
+	if _, ok := v.geo[field]; ok != (op == "NEAR" || op == "BBOX") {
+		return fmt.Errorf("operator %s is not allowed for field %q", op, field)
+	}
+	if v.permittedOps != nil {
+		if _, ok := v.permittedOps[op]; !ok {
+			return fmt.Errorf("%w: %s on %q", ErrOperatorDenied, op, field)
+		}
+	}
+	f.name = field
+	if column, ok := v.shadow[field]; ok {
+		field = column
+	}
+	f.Field = field
 	f.Op = op
 	return nil
 }
@@ -2,16 +2,16 @@ package go_dbsearch
 
 import "testing"
 
-func TestIsFieldAllowed_Allowed(t *testing.T) {
-	AllowedFields["foo"] = true
-	if !IsFieldAllowed("foo") {
-		t.Fatalf("Expected field to be allowed")
+func TestValidateField_Allowed(t *testing.T) {
+	v := NewValidator(map[string]struct{}{"foo": {}})
+	if err := v.ValidateField("foo"); err != nil {
+		t.Fatalf("Expected field to be allowed, got: %v", err)
 	}
 }
 
-func TestIsFieldAllowed_Disallowed(t *testing.T) {
-	AllowedFields["bar"] = false
-	if IsFieldAllowed("bar") {
+func TestValidateField_Disallowed(t *testing.T) {
+	v := NewValidator(map[string]struct{}{"foo": {}})
+	if err := v.ValidateField("bar"); err == nil {
 		t.Fatalf("Expected field to be disallowed")
 	}
 }